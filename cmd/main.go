@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gorilla/mux"
@@ -10,52 +12,237 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/DinizJ/desafio/internal/config"
 	"github.com/DinizJ/desafio/internal/handler"
+	"github.com/DinizJ/desafio/internal/migrate"
 	"github.com/DinizJ/desafio/internal/repository"
 	"github.com/DinizJ/desafio/internal/service"
 )
 
 func main() {
 
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
+	dbConfig, err := config.LoadDatabaseConfig()
+	if err != nil {
+		log.Fatalf("Erro na configuração: %v", err)
+	}
 
-	dsn := dbUser + ":" + dbPassword + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName
-	log.Printf("Conectando em: %s", dsn)
+	pagination, err := config.LoadPaginationConfig()
+	if err != nil {
+		log.Fatalf("Erro na configuração: %v", err)
+	}
 
-	//conectar ao banco
-	db, err := sql.Open("mysql", dsn)
+	server, err := config.LoadServerConfig()
 	if err != nil {
-		log.Fatalf("Erro ao conectar: %s", err)
+		log.Fatalf("Erro na configuração: %v", err)
 	}
-	defer db.Close()
 
-	//Ping
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Erro ao fazer ping: %v", err)
+	idempotency, err := config.LoadIdempotencyConfig(server)
+	if err != nil {
+		log.Fatalf("Erro na configuração: %v", err)
+	}
+
+	// Subcomando: go run cmd/main.go migrate up|down|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(dbConfig, os.Args[2:])
+		return
+	}
+
+	var (
+		repo  repository.TaskRepositoryInterface
+		store repository.IdempotencyStore
+	)
+	if dbConfig.Driver == config.DriverMemory {
+		log.Printf("Usando repository em memória (os dados não são persistidos)")
+		repo = repository.NewMemoryTaskRepository()
+		store = repository.NewMemoryIdempotencyStore()
+	} else {
+		db := openDB(dbConfig)
+		defer db.Close()
+
+		prepareSchema(dbConfig, db)
+		sqlRepo := newRepository(dbConfig, db)
+		repo = sqlRepo
+		store = sqlRepo.Idempotency()
 	}
-	log.Printf("Conectado com sucesso")
 
 	//Inicializa as layers
-	repo := repository.NewTaskRepository(db)
-	svc := service.NewTaskService(repo)
+	svc := service.NewTaskService(repo, service.Config{
+		DefaultPageSize: pagination.DefaultLimit,
+		MaxPageSize:     pagination.MaxLimit,
+	})
 	hdl := handler.NewTaskHandler(svc)
+	// Idempotency-Key nas operações que o cliente pode reenviar após uma falha de rede
+	idem := handler.NewIdempotency(store, idempotency.TTL, idempotency.PendingTTL)
 
 	//Config das rotas
 	router := mux.NewRouter()
-	router.HandleFunc("/api/v1/tasks", hdl.CreateTask).Methods("POST")
+	router.HandleFunc("/api/v1/tasks", idem.Wrap(hdl.CreateTask)).Methods("POST")
 	router.HandleFunc("/api/v1/tasks", hdl.ListTask).Methods("GET")
+	router.HandleFunc("/api/v1/tasks:batch", idem.Wrap(hdl.BatchTasks)).Methods("POST")
+	// Rotas fixas antes de /tasks/{id}, senão o mux casa "trash"/"export"/"search"/"upcoming"/"ready" como id
+	router.HandleFunc("/api/v1/tasks/trash", hdl.ListTrash).Methods("GET")
+	router.HandleFunc("/api/v1/tasks/export", hdl.ExportTasks).Methods("GET")
+	router.HandleFunc("/api/v1/tasks/import", hdl.ImportTasks).Methods("POST")
+	router.HandleFunc("/api/v1/tasks/search", hdl.SearchTask).Methods("GET")
+	router.HandleFunc("/api/v1/tasks/upcoming", hdl.UpcomingTasks).Methods("GET")
+	router.HandleFunc("/api/v1/tasks/ready", hdl.WorkPlan).Methods("GET")
 	router.HandleFunc("/api/v1/tasks/{id}", hdl.GetTask).Methods("GET")
 	router.HandleFunc("/api/v1/tasks/{id}", hdl.UpdateTask).Methods("PUT")
+	router.HandleFunc("/api/v1/tasks/{id}", hdl.PatchTask).Methods("PATCH")
 	router.HandleFunc("/api/v1/tasks/{id}", hdl.DeleteTask).Methods("DELETE")
-	router.HandleFunc("/api/v1/tasks/{id}/complete", hdl.CompleteTask).Methods("PATCH")
+	router.HandleFunc("/api/v1/tasks/{id}/complete", idem.Wrap(hdl.CompleteTask)).Methods("PATCH")
+	router.HandleFunc("/api/v1/tasks/{id}/reopen", idem.Wrap(hdl.ReopenTask)).Methods("PATCH")
+	router.HandleFunc("/api/v1/tasks/{id}/{action:start|block|unblock|cancel|archive}", idem.Wrap(hdl.TransitionTask)).Methods("PATCH")
+	router.HandleFunc("/api/v1/tasks/{id}/restore", hdl.RestoreTask).Methods("POST")
+	router.HandleFunc("/api/v1/tasks/{id}/subtasks", hdl.ListSubtasks).Methods("GET")
+	router.HandleFunc("/api/v1/tasks/{id}/dependencies", hdl.GetDependencies).Methods("GET")
+	router.HandleFunc("/api/v1/tasks/{id}/dependencies", hdl.AddDependency).Methods("POST")
+	router.HandleFunc("/api/v1/tasks/{id}/dependencies/{blocker_id}", hdl.RemoveDependency).Methods("DELETE")
+	router.HandleFunc("/api/v1/tags", hdl.ListTags).Methods("GET")
+
+	// Rotas e métodos inexistentes também respondem em problem+json
+	router.NotFoundHandler = http.HandlerFunc(handler.NotFound)
+	router.MethodNotAllowedHandler = http.HandlerFunc(handler.MethodNotAllowed)
 
 	// Roda servidor
 	log.Println("Servidor rodando em :8080")
-	if err := http.ListenAndServe(":8080", router); err != nil {
+	srv := &http.Server{
+		Addr:    ":8080",
+		Handler: router,
+		// Menor que IDEMPOTENCY_PENDING_TTL (conferido no LoadIdempotencyConfig)
+		WriteTimeout: server.WriteTimeout,
+	}
+	if err := srv.ListenAndServe(); err != nil {
 		log.Fatalf("Erro ao rodar servidor: %v", err)
 	}
 }
+
+// openDB abre e testa a conexão com o banco configurado no DB_DRIVER
+func openDB(cfg config.DatabaseConfig) *sql.DB {
+	var (
+		db  *sql.DB
+		err error
+	)
+
+	//conectar ao banco
+	if cfg.Driver == config.DriverSQLite {
+		log.Printf("Usando SQLite em: %s", cfg.Name)
+		db, err = repository.OpenSQLite(cfg.Name)
+	} else {
+		log.Printf("Conectando (%s) em: %s:%s/%s", cfg.Driver, cfg.Host, cfg.Port, cfg.Name)
+		db, err = sql.Open(cfg.SQLDriverName(), cfg.DSN())
+	}
+	if err != nil {
+		log.Fatalf("Erro ao conectar: %s", err)
+	}
+
+	//Ping
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Erro ao fazer ping: %v", err)
+	}
+	log.Printf("Conectado com sucesso")
+
+	return db
+}
+
+// newRepository escolhe a implementação SQL do repository pelo DB_DRIVER
+func newRepository(cfg config.DatabaseConfig, db *sql.DB) *repository.TaskRepository {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		return repository.NewPostgresTaskRepository(db)
+	case config.DriverSQLite:
+		return repository.NewSQLiteTaskRepository(db)
+	default:
+		return repository.NewTaskRepository(db)
+	}
+}
+
+// prepareSchema aplica as migrations pendentes (se DB_AUTO_MIGRATE) e
+// impede o servidor de subir com o schema desatualizado ou à frente do código
+func prepareSchema(cfg config.DatabaseConfig, db *sql.DB) {
+	ctx := context.Background()
+
+	migrator, err := migrate.New(db, cfg.Driver)
+	if err != nil {
+		log.Fatalf("Erro ao carregar migrations: %v", err)
+	}
+
+	if cfg.AutoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Erro ao aplicar migrations: %v", err)
+		}
+		for _, m := range applied {
+			log.Printf("Migration aplicada: %03d_%s", m.Version, m.Name)
+		}
+	}
+
+	pending, err := migrator.Check(ctx)
+	if err != nil {
+		log.Fatalf("Schema incompatível: %v", err)
+	}
+	if pending > 0 {
+		log.Fatalf("Schema desatualizado: %d migration(s) pendente(s), rode \"migrate up\"", pending)
+	}
+}
+
+// runMigrateCommand trata "migrate up", "migrate down" e "migrate status"
+func runMigrateCommand(cfg config.DatabaseConfig, args []string) {
+	if cfg.Driver == config.DriverMemory {
+		log.Fatalf("O driver %q não usa migrations", cfg.Driver)
+	}
+	if len(args) != 1 {
+		log.Fatalf("Uso: migrate up|down|status")
+	}
+
+	db := openDB(cfg)
+	defer db.Close()
+
+	migrator, err := migrate.New(db, cfg.Driver)
+	if err != nil {
+		log.Fatalf("Erro ao carregar migrations: %v", err)
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("Migration aplicada: %03d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Erro ao aplicar migrations: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Printf("Nenhuma migration pendente")
+		}
+
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			log.Fatalf("Erro ao reverter migration: %v", err)
+		}
+		if reverted == nil {
+			log.Printf("Nenhuma migration para reverter")
+			return
+		}
+		log.Printf("Migration revertida: %03d_%s", reverted.Version, reverted.Name)
+
+	case "status":
+		list, err := migrator.Status(ctx)
+		for _, st := range list {
+			state := "pendente"
+			if st.Applied {
+				state = "aplicada em " + st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d_%-30s %s\n", st.Version, st.Name, state)
+		}
+		if err != nil {
+			log.Fatalf("Schema incompatível: %v", err)
+		}
+
+	default:
+		log.Fatalf("Subcomando desconhecido %q. Uso: migrate up|down|status", args[0])
+	}
+}
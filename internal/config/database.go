@@ -1 +1,109 @@
 package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// Drivers de armazenamento suportados (DB_DRIVER)
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite" // DB_NAME é o caminho do arquivo (ou ":memory:")
+	DriverMemory   = "memory" // sem banco: dados somem ao reiniciar, útil para demos e testes de integração
+)
+
+type DatabaseConfig struct {
+	Driver   string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	SSLMode  string // só PostgreSQL
+
+	// AutoMigrate aplica as migrations pendentes ao subir o servidor (DB_AUTO_MIGRATE, padrão true).
+	// Desligado, o servidor se recusa a subir com migrations pendentes.
+	AutoMigrate bool
+}
+
+// LoadDatabaseConfig lê a configuração do banco das variáveis de ambiente.
+// DB_DRIVER é opcional e por padrão usa MySQL.
+func LoadDatabaseConfig() (DatabaseConfig, error) {
+	cfg := DatabaseConfig{
+		Driver:   os.Getenv("DB_DRIVER"),
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		User:     os.Getenv("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+		Name:     os.Getenv("DB_NAME"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+	}
+
+	if cfg.Driver == "" {
+		cfg.Driver = DriverMySQL
+	}
+
+	cfg.AutoMigrate = true
+	if raw := os.Getenv("DB_AUTO_MIGRATE"); raw != "" {
+		autoMigrate, err := strconv.ParseBool(raw)
+		if err != nil {
+			return DatabaseConfig{}, fmt.Errorf("DB_AUTO_MIGRATE inválido: %q", raw)
+		}
+		cfg.AutoMigrate = autoMigrate
+	}
+
+	switch cfg.Driver {
+	case DriverMySQL:
+		if cfg.Port == "" {
+			cfg.Port = "3306"
+		}
+	case DriverPostgres:
+		if cfg.Port == "" {
+			cfg.Port = "5432"
+		}
+		if cfg.SSLMode == "" {
+			cfg.SSLMode = "disable"
+		}
+	case DriverSQLite:
+		if cfg.Name == "" {
+			cfg.Name = "tasks.db"
+		}
+	case DriverMemory:
+	default:
+		return DatabaseConfig{}, fmt.Errorf("DB_DRIVER inválido: %q (use %q, %q, %q ou %q)",
+			cfg.Driver, DriverMySQL, DriverPostgres, DriverSQLite, DriverMemory)
+	}
+
+	return cfg, nil
+}
+
+// DSN monta a string de conexão no formato do driver configurado.
+// No MySQL, parseTime=true é necessário para ler colunas TIMESTAMP direto em time.Time.
+func (c DatabaseConfig) DSN() string {
+	switch c.Driver {
+	case DriverPostgres:
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.User, c.Password),
+			Host:     c.Host + ":" + c.Port,
+			Path:     "/" + c.Name,
+			RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+		}
+		return dsn.String()
+	case DriverSQLite:
+		return c.Name
+	default:
+		return c.User + ":" + c.Password + "@tcp(" + c.Host + ":" + c.Port + ")/" + c.Name + "?parseTime=true"
+	}
+}
+
+// SQLDriverName é o nome registrado no database/sql para o driver configurado
+func (c DatabaseConfig) SQLDriverName() string {
+	if c.Driver == DriverPostgres {
+		return "pgx"
+	}
+	return c.Driver
+}
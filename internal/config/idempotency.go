@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Valores usados sem IDEMPOTENCY_TTL e IDEMPOTENCY_PENDING_TTL
+const (
+	DefaultIdempotencyTTL        = 24 * time.Hour
+	DefaultIdempotencyPendingTTL = time.Minute
+)
+
+// IdempotencyConfig define por quanto tempo as respostas de requisições com
+// Idempotency-Key são guardadas; depois disso a chave pode ser reutilizada.
+type IdempotencyConfig struct {
+	TTL time.Duration // IDEMPOTENCY_TTL, no formato do time.ParseDuration (ex.: "24h", "90m")
+	// PendingTTL (IDEMPOTENCY_PENDING_TTL) é quanto dura a reserva de uma requisição
+	// que ainda não respondeu: se ela cair no meio (panic, queda do processo), a chave
+	// volta a valer depois disso. Precisa ser maior que o SERVER_WRITE_TIMEOUT, senão a
+	// reserva de uma requisição lenta, mas viva, vence e um reenvio executa a operação de novo.
+	PendingTTL time.Duration
+}
+
+func LoadIdempotencyConfig(server ServerConfig) (IdempotencyConfig, error) {
+	cfg := IdempotencyConfig{TTL: DefaultIdempotencyTTL, PendingTTL: DefaultIdempotencyPendingTTL}
+
+	if raw := os.Getenv("IDEMPOTENCY_TTL"); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl <= 0 {
+			return IdempotencyConfig{}, fmt.Errorf("IDEMPOTENCY_TTL inválido: %q (use uma duração positiva, ex.: 24h)", raw)
+		}
+		cfg.TTL = ttl
+	}
+
+	if raw := os.Getenv("IDEMPOTENCY_PENDING_TTL"); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl <= 0 {
+			return IdempotencyConfig{}, fmt.Errorf("IDEMPOTENCY_PENDING_TTL inválido: %q (use uma duração positiva, ex.: 1m)", raw)
+		}
+		cfg.PendingTTL = ttl
+	}
+
+	if cfg.PendingTTL <= server.WriteTimeout {
+		return IdempotencyConfig{}, fmt.Errorf("IDEMPOTENCY_PENDING_TTL (%s) precisa ser maior que SERVER_WRITE_TIMEOUT (%s)", cfg.PendingTTL, server.WriteTimeout)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// PaginationConfig define o tamanho de página das listagens.
+// Zero significa "usar o padrão do service".
+type PaginationConfig struct {
+	DefaultLimit int // PAGE_SIZE_DEFAULT
+	MaxLimit     int // PAGE_SIZE_MAX
+}
+
+func LoadPaginationConfig() (PaginationConfig, error) {
+	var cfg PaginationConfig
+
+	vars := []struct {
+		name string
+		dest *int
+	}{
+		{"PAGE_SIZE_DEFAULT", &cfg.DefaultLimit},
+		{"PAGE_SIZE_MAX", &cfg.MaxLimit},
+	}
+
+	for _, v := range vars {
+		raw := os.Getenv(v.name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return PaginationConfig{}, fmt.Errorf("%s inválido: %q (use um inteiro positivo)", v.name, raw)
+		}
+		*v.dest = n
+	}
+
+	if cfg.DefaultLimit > 0 && cfg.MaxLimit > 0 && cfg.DefaultLimit > cfg.MaxLimit {
+		return PaginationConfig{}, fmt.Errorf("PAGE_SIZE_DEFAULT (%d) maior que PAGE_SIZE_MAX (%d)", cfg.DefaultLimit, cfg.MaxLimit)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Valor usado sem SERVER_WRITE_TIMEOUT
+const DefaultServerWriteTimeout = 30 * time.Second
+
+// ServerConfig define os limites do servidor HTTP
+type ServerConfig struct {
+	// WriteTimeout (SERVER_WRITE_TIMEOUT) é quanto uma requisição tem para ser respondida,
+	// contando da leitura do corpo
+	WriteTimeout time.Duration
+}
+
+func LoadServerConfig() (ServerConfig, error) {
+	cfg := ServerConfig{WriteTimeout: DefaultServerWriteTimeout}
+
+	if raw := os.Getenv("SERVER_WRITE_TIMEOUT"); raw != "" {
+		timeout, err := time.ParseDuration(raw)
+		if err != nil || timeout <= 0 {
+			return ServerConfig{}, fmt.Errorf("SERVER_WRITE_TIMEOUT inválido: %q (use uma duração positiva, ex.: 30s)", raw)
+		}
+		cfg.WriteTimeout = timeout
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// batchResult é o resultado de uma operação na resposta do lote
+type batchResult struct {
+	Index  int         `json:"index"`
+	Op     string      `json:"op"`
+	Status int         `json:"status"`
+	Task   *model.Task `json:"task,omitempty"`
+	Error  *Problem    `json:"error,omitempty"`
+}
+
+// --------------------------BATCH TASKS-------------------------------
+func (h *TaskHandler) BatchTasks(w http.ResponseWriter, r *http.Request) {
+
+	var req struct {
+		Mode       string `json:"mode"`
+		Operations []struct {
+			Op      string          `json:"op"`
+			ID      string          `json:"id"`
+			Cascade bool            `json:"cascade"`
+			Task    json.RawMessage `json:"task"` // corpo do create ou merge patch do update
+		} `json:"operations"`
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	ops := make([]service.BatchOperation, len(req.Operations))
+	for i, item := range req.Operations {
+		ops[i] = service.BatchOperation{Op: item.Op, ID: item.ID, Cascade: item.Cascade}
+
+		var dst any
+		switch item.Op {
+		case service.BatchCreate:
+			dst = &ops[i].Create
+		case service.BatchUpdate:
+			dst = &ops[i].Patch
+		}
+		if dst == nil || len(item.Task) == 0 {
+			continue
+		}
+		if err := decodeStrict(item.Task, dst); err != nil {
+			writeParamError(w, r, batchTaskError(i, err))
+			return
+		}
+	}
+
+	results, err := h.service.Batch(r.Context(), req.Mode, ops)
+	if err != nil {
+		writeError(w, r, err, "failed to apply batch")
+		return
+	}
+
+	resp := struct {
+		Mode      string        `json:"mode"`
+		Committed bool          `json:"committed"` // atomic: false quando o lote foi desfeito
+		Results   []batchResult `json:"results"`
+	}{Mode: req.Mode, Committed: true, Results: make([]batchResult, len(results))}
+
+	for i, result := range results {
+		item := batchResult{Index: i, Op: ops[i].Op, Task: result.Task}
+		switch {
+		case result.Err != nil:
+			p := problemFor(r, result.Err, "failed to apply operation")
+			item.Status, item.Error = p.Status, &p
+			if req.Mode == service.BatchAtomic {
+				resp.Committed = false
+			}
+		case ops[i].Op == service.BatchCreate:
+			item.Status = http.StatusCreated
+		case ops[i].Op == service.BatchDelete:
+			item.Status = http.StatusNoContent
+		default:
+			item.Status = http.StatusOK
+		}
+		resp.Results[i] = item
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// batchTaskError aponta o erro de decodificação para o task da operação i
+func batchTaskError(i int, err error) error {
+	field := fmt.Sprintf("operations[%d].task", i)
+	if pe, ok := err.(*paramError); ok {
+		return &paramError{field + "." + pe.param, pe.message}
+	}
+	return &paramError{field, err.Error()}
+}
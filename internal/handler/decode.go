@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// maxBodyBytes limita o corpo JSON das requisições
+const maxBodyBytes = 1 << 20
+
+// decodeJSON lê o corpo em dst recusando campos desconhecidos, JSON mal formado
+// e mais de um valor no corpo. Em caso de erro já responde 400 e devolve false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	defer r.Body.Close()
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeParamError(w, r, decodeError(err))
+		return false
+	}
+
+	if err := decodeStrict(body, dst); err != nil {
+		writeParamError(w, r, err)
+		return false
+	}
+	return true
+}
+
+// decodeStrict decodifica body em dst com as mesmas regras do decodeJSON; o erro já
+// vem pronto para o writeParamError
+func decodeStrict(body []byte, dst any) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field == "" {
+			typeErr.Field = locateTypeError(body, dst)
+		}
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return errors.New("request body must contain a single JSON object")
+	}
+	return nil
+}
+
+// decodeError aponta o campo quando o encoding/json informa qual é
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var maxErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return &paramError{typeErr.Field, fmt.Sprintf("%s must be %s", typeErr.Field, jsonType(typeErr.Type.Kind().String()))}
+	case errors.As(err, &maxErr):
+		return fmt.Errorf("request body too large (max %d bytes)", maxErr.Limit)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// O encoding/json não exporta esse erro; o nome vem entre aspas na mensagem
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &paramError{field, fmt.Sprintf("unknown field %q", field)}
+	case errors.Is(err, io.EOF):
+		return errors.New("request body is required")
+	}
+	return errors.New("invalid request format")
+}
+
+// locateTypeError acha o campo de um erro de tipo que o encoding/json não informa:
+// erros vindos de um UnmarshalJSON próprio (service.Optional) chegam sem o campo.
+// Decodifica campo a campo até achar o que falha; "" se não achar.
+func locateTypeError(body []byte, dst any) string {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(body, &raw) != nil {
+		return ""
+	}
+	typ := reflect.TypeOf(dst)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		value, ok := raw[name]
+		if !ok {
+			continue
+		}
+		if json.Unmarshal(value, reflect.New(typ.Field(i).Type).Interface()) != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// jsonType traduz o tipo Go esperado para o nome do tipo no JSON
+func jsonType(kind string) string {
+	switch kind {
+	case "string":
+		return "a string"
+	case "slice", "array":
+		return "an array"
+	case "struct", "map":
+		return "an object"
+	case "bool":
+		return "a boolean"
+	}
+	return "a number"
+}
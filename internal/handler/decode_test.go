@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantOK    bool
+		wantField string
+	}{
+		{name: "valid", body: `{"title": "Task", "tags": ["a"]}`, wantOK: true},
+		{name: "unknown field", body: `{"title": "Task", "titel": "typo"}`, wantField: "titel"},
+		{name: "wrong type", body: `{"title": 42}`, wantField: "title"},
+		{name: "malformed", body: `{"title": `},
+		{name: "empty body", body: ``},
+		{name: "trailing data", body: `{"title": "Task"} {}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(tt.body))
+
+			var dst service.CreateTaskInput
+			ok := decodeJSON(rec, req, &dst)
+			if ok != tt.wantOK {
+				t.Fatalf("expected ok=%v, got %v", tt.wantOK, ok)
+			}
+			if ok {
+				return
+			}
+
+			var p Problem
+			if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", rec.Code)
+			}
+			if tt.wantField != "" && (len(p.Errors) != 1 || p.Errors[0].Field != tt.wantField) {
+				t.Errorf("expected error on %s, got %+v", tt.wantField, p.Errors)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON_OptionalFieldType(t *testing.T) {
+	// O erro de tipo dentro de um Optional chega sem o campo; decodeJSON precisa achá-lo
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/1", strings.NewReader(`{"description": null, "tags": "a"}`))
+
+	var dst service.PatchTaskInput
+	if decodeJSON(rec, req, &dst) {
+		t.Fatal("expected decode to fail")
+	}
+
+	var p Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(p.Errors) != 1 || p.Errors[0].Field != "tags" || p.Errors[0].Message != "tags must be an array" {
+		t.Errorf("expected error on tags, got %+v", p.Errors)
+	}
+}
+
+func TestPatchTask_RequiresMergePatch(t *testing.T) {
+	h := &TaskHandler{}
+
+	for _, contentType := range []string{"", "application/json", "text/plain"} {
+		t.Run(contentType, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/tasks/1", strings.NewReader(`{"title": "Task"}`))
+			req.Header.Set("Content-Type", contentType)
+
+			h.PatchTask(rec, req)
+			if rec.Code != http.StatusUnsupportedMediaType {
+				t.Errorf("expected 415, got %d", rec.Code)
+			}
+			if got := rec.Header().Get("Accept-Patch"); got != "application/merge-patch+json" {
+				t.Errorf("expected Accept-Patch header, got %q", got)
+			}
+		})
+	}
+}
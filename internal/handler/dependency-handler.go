@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// --------------------------LIST DEPENDENCIES-------------------------------
+func (h *TaskHandler) GetDependencies(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	deps, err := h.service.GetDependencies(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "failed to get dependencies")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(deps); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------ADD DEPENDENCY-------------------------------
+func (h *TaskHandler) AddDependency(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var req struct {
+		BlockerID string `json:"blocker_id"` // task que precisa ser concluída antes
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	deps, err := h.service.AddDependency(r.Context(), id, req.BlockerID)
+	if err != nil {
+		writeError(w, r, err, "failed to add dependency")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(deps); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------REMOVE DEPENDENCY-------------------------------
+func (h *TaskHandler) RemoveDependency(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+
+	if err := h.service.RemoveDependency(r.Context(), vars["id"], vars["blocker_id"]); err != nil {
+		writeError(w, r, err, "failed to remove dependency")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --------------------------WORK PLAN-------------------------------
+func (h *TaskHandler) WorkPlan(w http.ResponseWriter, r *http.Request) {
+
+	plan, err := h.service.WorkPlan(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to build work plan")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(plan); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
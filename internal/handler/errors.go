@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// writeError traduz o erro do service para o status HTTP: NotFound vira 404,
+// Conflict 409, Precondition 412 e Validation 422 (com os campos inválidos), com a mensagem do
+// próprio erro. Qualquer outro erro é falha interna: vai para o log e o cliente
+// recebe só a mensagem genérica.
+func writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	p := problemFor(r, err, fallback)
+	writeProblem(w, r, p.Status, p.Detail, p.Errors...)
+}
+
+// problemFor monta o Problem de um erro do service, com as regras do writeError.
+// NotApplied (operação desfeita num lote atomic) vira 424.
+func problemFor(r *http.Request, err error, fallback string) Problem {
+	var fields []service.FieldError
+	var domainErr *service.Error
+	if errors.As(err, &domainErr) {
+		fields = domainErr.Fields
+	}
+
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return newProblem(r, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		return newProblem(r, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrPrecondition):
+		return newProblem(r, http.StatusPreconditionFailed, err.Error())
+	case errors.Is(err, service.ErrValidation):
+		return newProblem(r, http.StatusUnprocessableEntity, err.Error(), fields...)
+	case errors.Is(err, service.ErrNotApplied):
+		return newProblem(r, http.StatusFailedDependency, err.Error())
+	default:
+		log.Printf("%s: %v", fallback, err)
+		return newProblem(r, http.StatusInternalServerError, fallback)
+	}
+}
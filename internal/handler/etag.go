@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// etag identifica a versão da task (coluna version); muda a cada alteração
+func etag(task *model.Task) string {
+	return `"` + strconv.FormatInt(task.Version, 10) + `"`
+}
+
+// ifMatchVersion lê a versão esperada do If-Match, para o service conferir junto com a escrita.
+// Sem header devolve 0: qualquer versão serve. "*" devolve service.AnyVersion: qualquer versão,
+// mas a task precisa existir (RFC 9110 §13.1.1). A comparação é forte: ETag fraco, malformado
+// ou lista com mais de um valor nunca casa, e vira -1.
+func ifMatchVersion(r *http.Request) int64 {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch header {
+	case "":
+		return 0
+	case "*":
+		return service.AnyVersion
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return -1
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return -1
+	}
+	return version
+}
+
+// notModified diz se algum ETag do If-None-Match é o atual; a comparação é
+// fraca, então W/"3" também casa com "3"
+func notModified(r *http.Request, current string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int64
+	}{
+		{header: "", want: 0},
+		{header: "*", want: service.AnyVersion},
+		{header: `"3"`, want: 3},
+		{header: ` "3" `, want: 3},
+		{header: `W/"3"`, want: -1}, // If-Match usa comparação forte
+		{header: `"3", "4"`, want: -1},
+		{header: "3", want: -1},
+		{header: `"abc"`, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/tasks/1", nil)
+			req.Header.Set("If-Match", tt.header)
+			if got := ifMatchVersion(req); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: `"3"`, want: true},
+		{header: `W/"3"`, want: true},
+		{header: `"1", "3"`, want: true},
+		{header: "*", want: true},
+		{header: `"2"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/1", nil)
+			req.Header.Set("If-None-Match", tt.header)
+			if got := notModified(req, `"3"`); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
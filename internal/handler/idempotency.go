@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// maxIdempotencyKeyLength é o tamanho máximo do header Idempotency-Key (coluna idempotency_key)
+const maxIdempotencyKeyLength = 255
+
+// Idempotency faz o reenvio de uma requisição com o mesmo Idempotency-Key receber a
+// resposta gravada da primeira, sem executar a operação de novo. A mesma chave com
+// outra requisição (método, caminho ou corpo diferentes) é recusada com 422.
+type Idempotency struct {
+	store      repository.IdempotencyStore
+	ttl        time.Duration // quanto a resposta fica guardada
+	pendingTTL time.Duration // quanto dura a reserva de uma requisição que não respondeu
+	now        func() time.Time
+}
+
+func NewIdempotency(store repository.IdempotencyStore, ttl, pendingTTL time.Duration) *Idempotency {
+	return &Idempotency{store: store, ttl: ttl, pendingTTL: pendingTTL, now: time.Now}
+}
+
+// Wrap aplica a idempotência a um handler; requisições sem o header passam direto
+func (m *Idempotency) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeParamError(w, r, &paramError{"Idempotency-Key", "Idempotency-Key is too long (max 255)"})
+			return
+		}
+
+		// O corpo é lido aqui para a impressão digital e devolvido intacto ao handler
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		r.Body.Close()
+		if err != nil {
+			writeParamError(w, r, decodeError(err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// A reserva vence logo: se a requisição nunca responder, a chave não fica presa até o TTL.
+		// O Owner identifica esta requisição no Complete/Release.
+		now := m.now()
+		record := model.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: fingerprint(r, body),
+			Owner:       uuid.NewString(),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(m.pendingTTL),
+		}
+
+		existing, err := m.store.Reserve(r.Context(), record)
+		if err != nil {
+			log.Printf("failed to reserve idempotency key: %v", err)
+			writeProblem(w, r, http.StatusInternalServerError, "failed to process idempotency key")
+			return
+		}
+		if existing != nil {
+			m.replay(w, r, record, existing)
+			return
+		}
+
+		// A operação é cancelada antes de a reserva vencer, para um reenvio nunca rodar junto com ela
+		opCtx, cancel := context.WithTimeout(r.Context(), m.pendingTTL)
+		defer cancel()
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r.WithContext(opCtx))
+
+		// Grava mesmo se o cliente já desconectou: é justamente o caso do reenvio
+		ctx := context.WithoutCancel(r.Context())
+		if !finalStatus(rec.status) {
+			// Não é a resposta definitiva: libera a chave para o reenvio tentar de novo
+			if err := m.store.Release(ctx, key, record.Owner); err != nil {
+				logStoreError("release idempotency key", key, err)
+			}
+			return
+		}
+
+		record.ExpiresAt = m.now().Add(m.ttl)
+		record.StatusCode = rec.status
+		record.ContentType = rec.Header().Get("Content-Type")
+		record.ETag = rec.Header().Get("ETag")
+		record.Body = rec.body.Bytes()
+		if err := m.store.Complete(ctx, record); err != nil {
+			logStoreError("store idempotent response", key, err)
+		}
+	}
+}
+
+// logStoreError registra a falha do Complete/Release. Reserva perdida não é falha do banco:
+// a chave já é de outra requisição (ou venceu), e o registro dela fica como está.
+func logStoreError(action, key string, err error) {
+	if errors.Is(err, repository.ErrReservationLost) {
+		log.Printf("idempotency key %q: reservation expired before the response, did not %s", key, action)
+		return
+	}
+	log.Printf("failed to %s: %v", action, err)
+}
+
+// finalStatus diz se a resposta é definitiva e pode ser repetida no reenvio: sucesso ou
+// um erro que o reenvio receberia igual (400, 404, 422). 409, 412, 429 e 5xx dependem do
+// momento, e o cliente que tentar de novo precisa ter a operação executada outra vez.
+func finalStatus(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity:
+		return true
+	}
+	return status < http.StatusBadRequest
+}
+
+// replay responde um reenvio a partir do registro gravado pela primeira requisição
+func (m *Idempotency) replay(w http.ResponseWriter, r *http.Request, record model.IdempotencyRecord, existing *model.IdempotencyRecord) {
+	switch {
+	case existing.Fingerprint != record.Fingerprint:
+		writeProblem(w, r, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request",
+			service.FieldError{Field: "Idempotency-Key", Message: "Idempotency-Key was already used with a different request"})
+	case existing.Pending():
+		writeProblem(w, r, http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+	default:
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		if existing.ETag != "" {
+			w.Header().Set("ETag", existing.ETag)
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(existing.StatusCode)
+		w.Write(existing.Body)
+	}
+}
+
+// fingerprint identifica a requisição: a mesma chave só vale para o mesmo método,
+// caminho (com a query, ex.: ?cascade=true) e corpo
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder repassa a resposta ao cliente e guarda uma cópia dela
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+func TestIdempotency(t *testing.T) {
+	store := repository.NewMemoryIdempotencyStore()
+	idem := NewIdempotency(store, time.Hour, time.Minute)
+
+	calls := 0
+	status := http.StatusCreated
+	create := idem.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"1"`)
+		w.WriteHeader(status)
+		w.Write(body)
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", key)
+		create(rec, req)
+		return rec
+	}
+
+	first := send("k1", `{"title":"A"}`)
+	if first.Code != http.StatusCreated || first.Body.String() != `{"title":"A"}` {
+		t.Fatalf("expected handler response, got %d %s", first.Code, first.Body)
+	}
+
+	// Reenvio: mesma resposta, sem executar de novo
+	retry := send("k1", `{"title":"A"}`)
+	if calls != 1 {
+		t.Errorf("expected handler called once, got %d", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() ||
+		retry.Header().Get("ETag") != `"1"` || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected replayed response, got %d %v %s", retry.Code, retry.Header(), retry.Body)
+	}
+
+	// Mesma chave, outro corpo
+	if rec := send("k1", `{"title":"B"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for reused key, got %d", rec.Code)
+	}
+
+	// Requisição original ainda em andamento
+	now := time.Now()
+	store.Reserve(context.Background(), model.IdempotencyRecord{Key: "k2", Fingerprint: fingerprint(httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil), []byte(`{}`)), CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	if rec := send("k2", `{}`); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 while pending, got %d", rec.Code)
+	}
+
+	// Falha interna libera a chave: o reenvio executa de novo
+	status = http.StatusInternalServerError
+	send("k3", `{}`)
+	status = http.StatusCreated
+	if rec := send("k3", `{}`); rec.Code != http.StatusCreated || calls != 3 {
+		t.Errorf("expected retry after 500 to run again, got %d (calls %d)", rec.Code, calls)
+	}
+
+	// Erros que dependem do momento liberam a chave; os definitivos são repetidos
+	for _, tt := range []struct {
+		key    string
+		status int
+		replay bool
+	}{
+		{"k4", http.StatusConflict, false},
+		{"k5", http.StatusPreconditionFailed, false},
+		{"k6", http.StatusTooManyRequests, false},
+		{"k7", http.StatusNotFound, true},
+		{"k8", http.StatusUnprocessableEntity, true},
+	} {
+		status = tt.status
+		send(tt.key, `{}`)
+		before := calls
+		rec := send(tt.key, `{}`)
+		if replayed := rec.Header().Get("Idempotent-Replayed") == "true"; replayed != tt.replay || (calls == before) != tt.replay {
+			t.Errorf("status %d: expected replay=%v, got replayed=%v (calls %d -> %d)", tt.status, tt.replay, replayed, before, calls)
+		}
+	}
+	status = http.StatusCreated
+
+	// Reserva de uma requisição que nunca respondeu vence com o pendingTTL, não com o TTL
+	store.Reserve(context.Background(), model.IdempotencyRecord{Key: "k9", Fingerprint: fingerprint(httptest.NewRequest(http.MethodPost, "/api/v1/tasks", nil), []byte(`{}`)), CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+	idem.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if rec := send("k9", `{}`); rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("expected abandoned key to run again after pending TTL, got %d", rec.Code)
+	}
+	// A resposta gravada fica o TTL inteiro
+	idem.now = func() time.Time { return now.Add(30 * time.Minute) }
+	if rec := send("k9", `{}`); rec.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected completed key replayed within TTL, got %d", rec.Code)
+	}
+	idem.now = time.Now
+
+	// Requisição lenta perde a reserva para um reenvio: a resposta dela não sobrescreve a do reenvio
+	slow := idem.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		later := time.Now().Add(2 * time.Minute)
+		store.Reserve(r.Context(), model.IdempotencyRecord{Key: "k10", Fingerprint: "retry", Owner: "retry", CreatedAt: later, ExpiresAt: later.Add(time.Minute)})
+		w.WriteHeader(http.StatusCreated)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{}`))
+	req.Header.Set("Idempotency-Key", "k10")
+	slow(httptest.NewRecorder(), req)
+	if existing, _ := store.Reserve(context.Background(), model.IdempotencyRecord{Key: "k10", CreatedAt: now, ExpiresAt: now}); existing == nil || existing.Owner != "retry" || !existing.Pending() {
+		t.Errorf("expected retry reservation kept, got %+v", existing)
+	}
+
+	// Sem header: sempre executa
+	before := calls
+	send("", `{}`)
+	send("", `{}`)
+	if calls != before+2 {
+		t.Errorf("expected requests without key to always run")
+	}
+}
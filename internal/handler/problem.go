@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// Problem é o corpo de erro da API (RFC 7807, application/problem+json)
+type Problem struct {
+	Type     string               `json:"type"`
+	Title    string               `json:"title"`
+	Status   int                  `json:"status"`
+	Detail   string               `json:"detail,omitempty"`
+	Instance string               `json:"instance,omitempty"`
+	Errors   []service.FieldError `json:"errors,omitempty"` // campos inválidos, em erros de validação
+}
+
+// problemTypes identifica cada tipo de problema; status sem tipo próprio usam about:blank
+var problemTypes = map[int]string{
+	http.StatusBadRequest:           "/problems/bad-request",
+	http.StatusNotFound:             "/problems/not-found",
+	http.StatusMethodNotAllowed:     "/problems/method-not-allowed",
+	http.StatusConflict:             "/problems/conflict",
+	http.StatusPreconditionFailed:   "/problems/precondition-failed",
+	http.StatusUnsupportedMediaType: "/problems/unsupported-media-type",
+	http.StatusFailedDependency:     "/problems/not-applied",
+	http.StatusUnprocessableEntity:  "/problems/validation-error",
+	http.StatusInternalServerError:  "/problems/internal-error",
+}
+
+// writeProblem escreve o erro como problem+json; instance é o caminho da requisição
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string, fields ...service.FieldError) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(newProblem(r, status, detail, fields...)) // o status já foi enviado: se falhar, não há o que fazer
+}
+
+// newProblem monta o corpo de erro; também usado nos resultados do lote
+func newProblem(r *http.Request, status int, detail string, fields ...service.FieldError) Problem {
+	problemType, ok := problemTypes[status]
+	if !ok {
+		problemType = "about:blank"
+	}
+	return Problem{
+		Type:     problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Errors:   fields,
+	}
+}
+
+// NotFound responde rotas inexistentes no mesmo formato dos demais erros
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusNotFound, "route not found")
+}
+
+// MethodNotAllowed responde métodos não suportados por uma rota existente
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusMethodNotAllowed, "method not allowed")
+}
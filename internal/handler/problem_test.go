@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+func TestWriteError(t *testing.T) {
+	validation := &service.Error{
+		Kind:    service.ErrValidation,
+		Message: "invalid priority",
+		Fields:  []service.FieldError{{Field: "priority", Message: "invalid priority"}},
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantType   string
+		wantDetail string
+		wantFields int
+	}{
+		{"not found", &service.Error{Kind: service.ErrNotFound, Message: "task not found"}, http.StatusNotFound, "/problems/not-found", "task not found", 0},
+		{"conflict", &service.Error{Kind: service.ErrConflict, Message: "task already completed"}, http.StatusConflict, "/problems/conflict", "task already completed", 0},
+		{"validation", validation, http.StatusUnprocessableEntity, "/problems/validation-error", "invalid priority", 1},
+		{"wrapped validation", fmt.Errorf("update: %w", validation), http.StatusUnprocessableEntity, "/problems/validation-error", "update: invalid priority", 1},
+		// Erro interno não vaza a mensagem original
+		{"internal", errors.New("dial tcp: connection refused"), http.StatusInternalServerError, "/problems/internal-error", "failed to get task", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/42", nil)
+
+			writeError(rec, req, tt.err, "failed to get task")
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("expected problem+json, got %q", ct)
+			}
+
+			var p Problem
+			if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if p.Type != tt.wantType || p.Status != tt.wantStatus || p.Detail != tt.wantDetail {
+				t.Errorf("unexpected problem: %+v", p)
+			}
+			if p.Title != http.StatusText(tt.wantStatus) || p.Instance != "/api/v1/tasks/42" {
+				t.Errorf("unexpected title/instance: %+v", p)
+			}
+			if len(p.Errors) != tt.wantFields {
+				t.Errorf("expected %d field errors, got %v", tt.wantFields, p.Errors)
+			}
+		})
+	}
+}
+
+func TestWriteParamError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks?limit=x", nil)
+
+	_, err := parseListQuery(req.URL.Query())
+	writeParamError(rec, req, err)
+
+	var p Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusBadRequest || len(p.Errors) != 1 || p.Errors[0].Field != "limit" {
+		t.Errorf("expected 400 pointing at limit, got %d %+v", rec.Code, p)
+	}
+}
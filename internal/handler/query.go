@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// Parsing dos query params de listagem. Só valida o formato (datas, listas);
+// os valores permitidos são validados no service.
+
+// paramError é um parâmetro da requisição (query ou corpo) ausente ou mal formado;
+// vira 400 apontando o parâmetro em errors
+type paramError struct {
+	param   string
+	message string
+}
+
+func (e *paramError) Error() string { return e.message }
+
+// writeParamError responde 400; erros que não são paramError vão sem errors
+func writeParamError(w http.ResponseWriter, r *http.Request, err error) {
+	var pe *paramError
+	if errors.As(err, &pe) {
+		writeProblem(w, r, http.StatusBadRequest, pe.message, service.FieldError{Field: pe.param, Message: pe.message})
+		return
+	}
+	writeProblem(w, r, http.StatusBadRequest, err.Error())
+}
+
+// parseListQuery lê filtros, sort, limit e cursor das listagens
+func parseListQuery(query url.Values) (service.ListQuery, error) {
+	filter, err := parseTaskFilter(query)
+	if err != nil {
+		return service.ListQuery{}, err
+	}
+
+	// limit é opcional; o service aplica o padrão e o máximo configurados
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return service.ListQuery{}, &paramError{"limit", "limit must be a positive integer"}
+		}
+	}
+
+	return service.ListQuery{
+		Filter: filter,
+		Sort:   query.Get("sort"),
+		Limit:  limit,
+		Cursor: query.Get("cursor"),
+	}, nil
+}
+
+// parseTaskFilter lê os filtros do GET /tasks:
+// status, priority e tag aceitam lista (priority=high,medium ou priority=high&priority=medium);
+// tag_mode=all exige todas as tags (padrão: qualquer uma);
+// datas aceitam RFC 3339 ou YYYY-MM-DD (meia-noite UTC); overdue aceita true/false.
+func parseTaskFilter(query url.Values) (model.TaskFilter, error) {
+	filter := model.TaskFilter{
+		Statuses:            parseList(query, "status"),
+		Priorities:          parseList(query, "priority"),
+		ParentID:            query.Get("parent_id"),
+		Tags:                parseList(query, "tag"),
+		TagMode:             query.Get("tag_mode"),
+		TitleContains:       query.Get("title"),
+		DescriptionContains: query.Get("description"),
+	}
+
+	dates := []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+		{"updated_after", &filter.UpdatedAfter},
+		{"updated_before", &filter.UpdatedBefore},
+		{"due_after", &filter.DueAfter},
+		{"due_before", &filter.DueBefore},
+	}
+
+	for _, d := range dates {
+		raw := query.Get(d.param)
+		if raw == "" {
+			continue
+		}
+		t, err := parseDate(raw)
+		if err != nil {
+			return model.TaskFilter{}, &paramError{d.param, fmt.Sprintf("invalid %s: use RFC 3339 or YYYY-MM-DD", d.param)}
+		}
+		*d.dest = &t
+	}
+
+	if raw := query.Get("overdue"); raw != "" {
+		overdue, err := strconv.ParseBool(raw)
+		if err != nil {
+			return model.TaskFilter{}, &paramError{"overdue", "invalid overdue: use true or false"}
+		}
+		filter.Overdue = &overdue
+	}
+
+	return filter, nil
+}
+
+// parseList junta valores repetidos e separados por vírgula, ignorando vazios
+func parseList(query url.Values, param string) []string {
+	var values []string
+	for _, raw := range query[param] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+func parseDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
+}
+
+// maxWithinDays limita os dias do within: sem limite, N * 24h estoura o time.Duration
+const maxWithinDays = 365
+
+// parseWithin aceita as durações do Go (72h, 90m) e também dias inteiros (3d, até maxWithinDays)
+func parseWithin(raw string) (time.Duration, error) {
+	invalid := &paramError{"within", "within must be a positive duration (e.g. 72h, 3d)"}
+
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, invalid
+		}
+		if n > maxWithinDays {
+			return 0, &paramError{"within", fmt.Sprintf("within must be at most %dd", maxWithinDays)}
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	within, err := time.ParseDuration(raw)
+	if err != nil || within <= 0 {
+		return 0, invalid
+	}
+	return within, nil
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWithin(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "72h", want: 72 * time.Hour},
+		{raw: "3d", want: 72 * time.Hour},
+		{raw: "365d", want: 365 * 24 * time.Hour},
+		{raw: "366d", wantErr: true},
+		// N * 24h estouraria o time.Duration
+		{raw: "999999999999d", wantErr: true},
+		{raw: "0d", wantErr: true},
+		{raw: "-1d", wantErr: true},
+		{raw: "-5h", wantErr: true},
+		{raw: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseWithin(tt.raw)
+			if tt.wantErr {
+				if _, ok := err.(*paramError); !ok {
+					t.Errorf("expected paramError, got %v (%s)", err, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expected %s, got %s (%v)", tt.want, got, err)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -21,39 +24,27 @@ func NewTaskHandler(svc *service.TaskService) *TaskHandler {
 // --------------------------CREATE TASK-------------------------------
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 
-	//Parse request
-	var req struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request format", http.StatusBadRequest)
-		return
-	}
-
-	defer r.Body.Close()
-
-	//Validar entrada
-	if req.Title == "" {
-		http.Error(w, "title is required", http.StatusBadRequest)
+	//Parse request: os campos aceitos e as regras de validação ficam no DTO do service
+	var req service.CreateTaskInput
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	//Chama service
-	task, err := h.service.CreateTask(r.Context(), req.Title, req.Description)
+	task, err := h.service.CreateTask(r.Context(), req)
 	//r.Context() é cancelado se o cliente fechar a conexão ou der timeout!
 	if err != nil {
 		//erro em service
-		http.Error(w, "failed to create task", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to create task")
 		return
 	}
 
+	w.Header().Set("ETag", etag(task))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	//Erro ja tratado!
 	if err := json.NewEncoder(w).Encode(task); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
 	}
 }
 
@@ -64,19 +55,21 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 
 	task, err := h.service.GetTask(r.Context(), id)
 	if err != nil {
-		http.Error(w, "failed to get task", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to get task")
 		return
 	}
 
-	if task == nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+	// O cliente já tem esta versão: não reenvia o corpo
+	w.Header().Set("ETag", etag(task))
+	if notModified(r, etag(task)) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(task); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
 	}
 }
 
@@ -86,39 +79,65 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var req struct {
-		Title       string `json:"title"`
-		Description string `json:"description"`
-		Status      string `json:"status"`
-		Priority    string `json:"priority"`
+	var req service.UpdateTaskInput
+	if !decodeJSON(w, r, &req) {
+		return
 	}
 
-	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid request format", http.StatusBadRequest)
+	//If-Match: só grava se a task ainda estiver na versão que o cliente leu
+	task, created, err := h.service.UpsertTask(r.Context(), id, req, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err, "failed to update task")
 		return
 	}
 
-	if req.Title == "" {
-		http.Error(w, "title is required", http.StatusBadRequest)
+	//Id novo: a task foi criada
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	w.Header().Set("ETag", etag(task))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(task); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------PATCH TASK-------------------------------
+// mergePatchType é o único formato aceito no PATCH /tasks/{id} (RFC 7396)
+const mergePatchType = "application/merge-patch+json"
+
+func (h *TaskHandler) PatchTask(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != mergePatchType {
+		w.Header().Set("Accept-Patch", mergePatchType)
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "content type must be "+mergePatchType)
 		return
 	}
 
-	task, err := h.service.UpdateTask(r.Context(), id, req.Title, req.Description, req.Status, req.Priority)
-	if err != nil {
-		http.Error(w, "failed to update task", http.StatusInternalServerError)
+	//Campo ausente mantém o valor atual, null limpa
+	var req service.PatchTaskInput
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	if task == nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+	task, err := h.service.PatchTask(r.Context(), id, req, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err, "failed to update task")
 		return
 	}
 
+	w.Header().Set("ETag", etag(task))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(task); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
 	}
 }
 
@@ -129,17 +148,33 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	if id == "" {
-		http.Error(w, "id is required", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "id is required")
 		return
 	}
 
-	err := h.service.DeleteTask(r.Context(), id)
+	// ?purge=true remove definitivamente em vez de mandar para a lixeira
+	purge := false
+	if raw := r.URL.Query().Get("purge"); raw != "" {
+		var err error
+		purge, err = strconv.ParseBool(raw)
+		if err != nil {
+			writeParamError(w, r, &paramError{"purge", "invalid purge value"})
+			return
+		}
+	}
+
+	var err error
+	if purge {
+		err = h.service.PurgeTask(r.Context(), id, ifMatchVersion(r))
+	} else {
+		err = h.service.DeleteTask(r.Context(), id, ifMatchVersion(r))
+	}
 	if err != nil {
 		// CORREÇÃO: Removido o bloco "if err.Error == nil" que causava erro de compilação.
 		// Error é um método, não um campo. Além disso, a validação de "task not found"
 		// já é feita no service (DeleteTask verifica se existe antes de deletar).
 		// Se houver erro, retornamos 500. O service já retorna erro descritivo.
-		http.Error(w, "failed to delete task", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to delete task")
 		return
 	}
 
@@ -150,18 +185,22 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 // --------------------------LIST TASK-------------------------------
 func (h *TaskHandler) ListTask(w http.ResponseWriter, r *http.Request) {
 
-	status := r.URL.Query().Get("status")
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
 
-	tasks, err := h.service.ListTask(r.Context(), status)
+	page, err := h.service.ListTask(r.Context(), q)
 	if err != nil {
-		http.Error(w, "failed to list tasks", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to list tasks")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(tasks); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
 	}
 }
 
@@ -171,23 +210,254 @@ func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	if id == "" {
-		http.Error(w, "id is required", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	// ?cascade=true conclui junto as subtasks abertas
+	cascade := false
+	if raw := r.URL.Query().Get("cascade"); raw != "" {
+		var err error
+		cascade, err = strconv.ParseBool(raw)
+		if err != nil {
+			writeParamError(w, r, &paramError{"cascade", "invalid cascade value"})
+			return
+		}
+	}
+
+	task, err := h.service.CompleteTask(r.Context(), id, cascade, ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err, "failed to complete task")
+		return
+	}
+
+	w.Header().Set("ETag", etag(task))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(task); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------TRANSITION TASK-------------------------------
+// TransitionTask atende PATCH /tasks/{id}/{action} (start, block, unblock, cancel, archive);
+// quais ações valem para cada status é decidido no service
+func (h *TaskHandler) TransitionTask(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	id, action := vars["id"], vars["action"]
+
+	if id == "" {
+		writeProblem(w, r, http.StatusBadRequest, "id is required")
 		return
 	}
 
-	task, err := h.service.CompleteTask(r.Context(), id)
+	task, err := h.service.TransitionTask(r.Context(), id, action, ifMatchVersion(r))
 	if err != nil {
-		http.Error(w, "failed to complete task", http.StatusInternalServerError)
+		writeError(w, r, err, "failed to "+action+" task")
 		return
 	}
-	if task == nil {
-		http.Error(w, "task not found", http.StatusNotFound)
+
+	w.Header().Set("ETag", etag(task))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(task); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------REOPEN TASK-------------------------------
+// ReopenTask volta a task para pending; quem reabriu vem do header X-User (opcional)
+func (h *TaskHandler) ReopenTask(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		writeProblem(w, r, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	task, err := h.service.ReopenTask(r.Context(), id, r.Header.Get("X-User"), ifMatchVersion(r))
+	if err != nil {
+		writeError(w, r, err, "failed to reopen task")
 		return
 	}
 
+	w.Header().Set("ETag", etag(task))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(task); err != nil {
-		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------LIST SUBTASKS-------------------------------
+func (h *TaskHandler) ListSubtasks(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Mesmos filtros, ordenação e paginação da listagem
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	page, err := h.service.ListSubtasks(r.Context(), id, q)
+	if err != nil {
+		writeError(w, r, err, "failed to list subtasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------LIST TRASH-------------------------------
+func (h *TaskHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+
+	tasks, err := h.service.ListTrash(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to list trash")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tasks); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------RESTORE TASK-------------------------------
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if id == "" {
+		writeProblem(w, r, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	task, err := h.service.RestoreTask(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "failed to restore task")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag(task))
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(task); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------UPCOMING TASKS-------------------------------
+func (h *TaskHandler) UpcomingTasks(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+
+	// within é opcional; o service aplica a janela padrão
+	var within time.Duration
+	if raw := query.Get("within"); raw != "" {
+		var err error
+		within, err = parseWithin(raw)
+		if err != nil {
+			writeParamError(w, r, err)
+			return
+		}
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			writeParamError(w, r, &paramError{"limit", "limit must be a positive integer"})
+			return
+		}
+	}
+
+	page, err := h.service.UpcomingTasks(r.Context(), service.UpcomingQuery{
+		Within: within,
+		Limit:  limit,
+		Cursor: query.Get("cursor"),
+	})
+	if err != nil {
+		writeError(w, r, err, "failed to list upcoming tasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------LIST TAGS-------------------------------
+func (h *TaskHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+
+	tags, err := h.service.ListTags(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to list tags")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(tags); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// --------------------------SEARCH TASK-------------------------------
+func (h *TaskHandler) SearchTask(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+
+	// Aceita os mesmos filtros da listagem
+	filter, err := parseTaskFilter(query)
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	limit := 0
+	if raw := query.Get("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			writeParamError(w, r, &paramError{"limit", "limit must be a positive integer"})
+			return
+		}
+	}
+
+	if query.Get("q") == "" {
+		writeParamError(w, r, &paramError{"q", "q is required"})
+		return
+	}
+
+	page, err := h.service.SearchTasks(r.Context(), service.SearchQuery{
+		Text:   query.Get("q"),
+		Filter: filter,
+		Limit:  limit,
+		Cursor: query.Get("cursor"),
+	})
+	if err != nil {
+		writeError(w, r, err, "failed to search tasks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
 	}
 }
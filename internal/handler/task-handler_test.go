@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/DinizJ/desafio/internal/repository"
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// Toda escrita sobre a task recusa um If-Match que não é a versão atual
+func TestTaskHandler_IfMatchOnWrites(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		vars   map[string]string
+		handle func(h *TaskHandler) http.HandlerFunc
+		setup  func(ctx context.Context, svc *service.TaskService, id string) // deixa a task no status de partida
+		want   int
+	}{
+		{name: "delete", method: http.MethodDelete, handle: func(h *TaskHandler) http.HandlerFunc { return h.DeleteTask }, want: http.StatusNoContent},
+		{name: "purge", method: http.MethodDelete, path: "?purge=true", handle: func(h *TaskHandler) http.HandlerFunc { return h.DeleteTask }, want: http.StatusNoContent},
+		{name: "complete", method: http.MethodPatch, path: "/complete", handle: func(h *TaskHandler) http.HandlerFunc { return h.CompleteTask }, want: http.StatusOK},
+		{name: "transition", method: http.MethodPatch, path: "/start", vars: map[string]string{"action": "start"}, handle: func(h *TaskHandler) http.HandlerFunc { return h.TransitionTask }, want: http.StatusOK},
+		{
+			name: "reopen", method: http.MethodPatch, path: "/reopen", handle: func(h *TaskHandler) http.HandlerFunc { return h.ReopenTask }, want: http.StatusOK,
+			setup: func(ctx context.Context, svc *service.TaskService, id string) {
+				if _, err := svc.CompleteTask(ctx, id, false, 0); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc := service.NewTaskService(repository.NewMemoryTaskRepository(), service.Config{})
+			h := NewTaskHandler(svc)
+
+			task, err := svc.CreateTask(ctx, service.CreateTaskInput{Title: "Task"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.setup != nil {
+				tt.setup(ctx, svc, task.ID)
+			}
+			current, _ := svc.GetTask(ctx, task.ID)
+
+			send := func(ifMatch string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(tt.method, "/api/v1/tasks/"+task.ID+tt.path, nil)
+				req.Header.Set("If-Match", ifMatch)
+				vars := map[string]string{"id": task.ID}
+				for k, v := range tt.vars {
+					vars[k] = v
+				}
+				rec := httptest.NewRecorder()
+				tt.handle(h)(rec, mux.SetURLVars(req, vars))
+				return rec
+			}
+
+			// ETag antigo: nada muda
+			if rec := send(`"99"`); rec.Code != http.StatusPreconditionFailed {
+				t.Fatalf("expected 412 for stale ETag, got %d %s", rec.Code, rec.Body)
+			}
+			if after, err := svc.GetTask(ctx, task.ID); err != nil || after.Version != current.Version || after.Status != current.Status {
+				t.Fatalf("expected task unchanged after 412, got %+v (%v)", after, err)
+			}
+
+			if rec := send(etag(current)); rec.Code != tt.want {
+				t.Errorf("expected %d for current ETag, got %d %s", tt.want, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+// Soft delete e restore mudam a versão: o restore devolve o ETag novo
+func TestTaskHandler_RestoreReturnsETag(t *testing.T) {
+	ctx := context.Background()
+	svc := service.NewTaskService(repository.NewMemoryTaskRepository(), service.Config{})
+	h := NewTaskHandler(svc)
+
+	task, err := svc.CreateTask(ctx, service.CreateTaskInput{Title: "Task"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.DeleteTask(ctx, task.ID, task.Version); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/"+task.ID+"/restore", nil)
+	rec := httptest.NewRecorder()
+	h.RestoreTask(rec, mux.SetURLVars(req, map[string]string{"id": task.ID}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body)
+	}
+
+	restored, err := svc.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored.Version != task.Version+2 || rec.Header().Get("ETag") != etag(restored) {
+		t.Errorf("expected ETag %s (version %d), got %q (version %d)", etag(restored), task.Version+2, rec.Header().Get("ETag"), restored.Version)
+	}
+}
@@ -0,0 +1,411 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+// Formatos de exportação e importação (?format=)
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl" // JSON Lines: uma task por linha
+)
+
+var formatContentTypes = map[string]string{
+	formatCSV:   "text/csv; charset=utf-8",
+	formatJSONL: "application/x-ndjson",
+}
+
+// maxImportBytes limita o arquivo importado (maior que o maxBodyBytes das demais rotas)
+const maxImportBytes = 10 << 20
+
+// csvColumns são as colunas do CSV, na ordem da exportação. Na importação a ordem é
+// livre e só title é obrigatória; completed_at, created_at e updated_at são ignoradas.
+var csvColumns = []string{"id", "parent_id", "title", "description", "status", "priority", "due_at", "tags", "completed_at", "created_at", "updated_at"}
+
+// taskRecord é a task no arquivo JSON Lines, com os mesmos campos do CSV
+type taskRecord struct {
+	ID          string     `json:"id"`
+	ParentID    *string    `json:"parent_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	Tags        []string   `json:"tags"`
+	CompletedAt *time.Time `json:"completed_at"` // só informativo: ignorado na importação
+	CreatedAt   time.Time  `json:"created_at"`   // idem
+	UpdatedAt   time.Time  `json:"updated_at"`   // idem
+}
+
+func newTaskRecord(task model.Task) taskRecord {
+	return taskRecord{
+		ID:          task.ID,
+		ParentID:    task.ParentID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		DueAt:       task.DueAt,
+		Tags:        task.Tags,
+		CompletedAt: task.CompletedAt,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+}
+
+// importRow converte o registro na linha a importar
+func (rec taskRecord) importRow(line int) service.ImportRow {
+	in := service.UpdateTaskInput{
+		Title:       rec.Title,
+		Description: rec.Description,
+		Status:      rec.Status,
+		Priority:    rec.Priority,
+		DueAt:       rec.DueAt,
+		Tags:        rec.Tags,
+	}
+	if rec.ParentID != nil {
+		in.ParentID = *rec.ParentID
+	}
+	return service.ImportRow{Line: line, ID: rec.ID, Task: in}
+}
+
+// parseFormat lê o ?format= da exportação e da importação
+func parseFormat(query url.Values) (string, error) {
+	format := query.Get("format")
+	if _, ok := formatContentTypes[format]; !ok {
+		return "", &paramError{"format", "format must be csv or jsonl"}
+	}
+	return format, nil
+}
+
+// --------------------------EXPORT TASKS-------------------------------
+// ExportTasks devolve as tasks dos filtros da listagem em CSV ou JSON Lines. As linhas
+// são escritas à medida que saem do banco, sem montar a lista inteira.
+func (h *TaskHandler) ExportTasks(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+	format, err := parseFormat(query)
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+	filter, err := parseTaskFilter(query)
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	enc := newTaskEncoder(format, w)
+
+	// Os headers só saem com a primeira task (ou no fim, se não houver nenhuma):
+	// até lá um erro ainda pode virar uma resposta de erro normal
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+		w.Header().Set("Content-Type", formatContentTypes[format])
+		w.Header().Set("Content-Disposition", `attachment; filename="tasks.`+format+`"`)
+		w.WriteHeader(http.StatusOK)
+		return enc.begin()
+	}
+
+	err = h.service.ExportTasks(r.Context(), filter, func(task model.Task) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return enc.encode(task)
+	})
+	if err == nil {
+		err = start()
+	}
+	if err == nil {
+		err = enc.flush()
+	}
+	if err == nil {
+		return
+	}
+
+	if !started {
+		writeError(w, r, err, "failed to export tasks")
+		return
+	}
+	// O 200 já foi enviado: corta a conexão para o arquivo pela metade não parecer completo
+	log.Printf("failed to export tasks: %v", err)
+	panic(http.ErrAbortHandler)
+}
+
+// taskEncoder escreve as tasks exportadas em um dos formatos
+type taskEncoder interface {
+	begin() error // cabeçalho do arquivo, se o formato tiver
+	encode(task model.Task) error
+	flush() error
+}
+
+func newTaskEncoder(format string, w io.Writer) taskEncoder {
+	if format == formatCSV {
+		return &csvTaskEncoder{w: csv.NewWriter(w)}
+	}
+	return &jsonlTaskEncoder{enc: json.NewEncoder(w)}
+}
+
+type csvTaskEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvTaskEncoder) begin() error {
+	return e.w.Write(csvColumns)
+}
+
+func (e *csvTaskEncoder) encode(task model.Task) error {
+	var parentID string
+	if task.ParentID != nil {
+		parentID = *task.ParentID
+	}
+	return e.w.Write([]string{
+		task.ID,
+		parentID,
+		task.Title,
+		task.Description,
+		task.Status,
+		task.Priority,
+		formatCSVTime(task.DueAt),
+		strings.Join(task.Tags, ","),
+		formatCSVTime(task.CompletedAt),
+		formatCSVTime(&task.CreatedAt),
+		formatCSVTime(&task.UpdatedAt),
+	})
+}
+
+func (e *csvTaskEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// formatCSVTime escreve a data em RFC 3339 (UTC); sem data, célula vazia
+func formatCSVTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type jsonlTaskEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlTaskEncoder) begin() error { return nil }
+
+// encode escreve a task e a quebra de linha (o json.Encoder já termina cada valor com "\n")
+func (e *jsonlTaskEncoder) encode(task model.Task) error {
+	return e.enc.Encode(newTaskRecord(task))
+}
+
+func (e *jsonlTaskEncoder) flush() error { return nil }
+
+// --------------------------IMPORT TASKS-------------------------------
+// ImportTasks cria ou substitui as tasks do arquivo (CSV ou JSON Lines), linha a linha,
+// e responde o resultado de cada uma. ?dry_run=true só valida: nada é gravado.
+func (h *TaskHandler) ImportTasks(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+	format, err := parseFormat(query)
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	dryRun := false
+	if raw := query.Get("dry_run"); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			writeParamError(w, r, &paramError{"dry_run", "invalid dry_run value"})
+			return
+		}
+	}
+
+	defer r.Body.Close()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		writeParamError(w, r, decodeError(err))
+		return
+	}
+
+	var rows []service.ImportRow
+	if format == formatCSV {
+		rows, err = parseCSVRows(body)
+	} else {
+		rows = parseJSONLRows(body)
+	}
+	if err != nil {
+		writeParamError(w, r, err)
+		return
+	}
+
+	results, err := h.service.ImportTasks(r.Context(), rows, dryRun)
+	if err != nil {
+		writeError(w, r, err, "failed to import tasks")
+		return
+	}
+
+	type rowResult struct {
+		Line   int      `json:"line"`
+		ID     string   `json:"id,omitempty"`
+		Result string   `json:"result"`
+		Error  *Problem `json:"error,omitempty"`
+	}
+	resp := struct {
+		DryRun  bool        `json:"dry_run"`
+		Total   int         `json:"total"`
+		Created int         `json:"created"`
+		Updated int         `json:"updated"`
+		Failed  int         `json:"failed"`
+		Rows    []rowResult `json:"rows"`
+	}{DryRun: dryRun, Total: len(results), Rows: make([]rowResult, len(results))}
+
+	for i, result := range results {
+		row := rowResult{Line: result.Line, ID: result.ID, Result: result.Result}
+		switch result.Result {
+		case service.ImportCreated:
+			resp.Created++
+		case service.ImportUpdated:
+			resp.Updated++
+		default:
+			resp.Failed++
+			p := problemFor(r, result.Err, "failed to import row")
+			row.Error = &p
+		}
+		resp.Rows[i] = row
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, "failed to encode response")
+	}
+}
+
+// parseCSVRows lê o CSV importado. A primeira linha tem os nomes das colunas; CSV mal
+// formado ou coluna desconhecida recusam o arquivo inteiro, já um valor inválido só a linha.
+func parseCSVRows(body []byte) ([]service.ImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Planilhas costumam salvar o CSV com BOM no início
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(csvColumns, name) {
+			return nil, &paramError{"columns", fmt.Sprintf("unknown column %q", name)}
+		}
+		if _, dup := columns[name]; dup {
+			return nil, &paramError{"columns", fmt.Sprintf("duplicate column %q", name)}
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, &paramError{"columns", `missing column "title"`}
+	}
+
+	var rows []service.ImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, csvRow(line, record, columns))
+	}
+}
+
+func csvRow(line int, record []string, columns map[string]int) service.ImportRow {
+	get := func(column string) string {
+		if i, ok := columns[column]; ok {
+			return record[i]
+		}
+		return ""
+	}
+
+	row := service.ImportRow{
+		Line: line,
+		ID:   strings.TrimSpace(get("id")),
+		Task: service.UpdateTaskInput{
+			Title:       get("title"),
+			Description: get("description"),
+			Status:      strings.TrimSpace(get("status")),
+			Priority:    strings.TrimSpace(get("priority")),
+			ParentID:    strings.TrimSpace(get("parent_id")),
+		},
+	}
+
+	// Tags separadas por vírgula dentro da célula, como no ?tag= da listagem
+	for _, tag := range strings.Split(get("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			row.Task.Tags = append(row.Task.Tags, tag)
+		}
+	}
+
+	if raw := strings.TrimSpace(get("due_at")); raw != "" {
+		dueAt, err := parseDate(raw)
+		if err != nil {
+			row.Err = rowError(&paramError{"due_at", "invalid due_at: use RFC 3339 or YYYY-MM-DD"})
+			return row
+		}
+		row.Task.DueAt = &dueAt
+	}
+	return row
+}
+
+// parseJSONLRows lê o JSON Lines importado; linhas em branco são ignoradas e uma
+// linha que não é uma task válida em JSON só marca aquela linha como inválida
+func parseJSONLRows(body []byte) []service.ImportRow {
+	var rows []service.ImportRow
+	for i, line := range bytes.Split(body, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var rec taskRecord
+		if err := decodeStrict(line, &rec); err != nil {
+			rows = append(rows, service.ImportRow{Line: i + 1, Err: rowError(err)})
+			continue
+		}
+		rows = append(rows, rec.importRow(i+1))
+	}
+	return rows
+}
+
+// rowError transforma um erro de leitura da linha em erro de validação (422) da linha
+func rowError(err error) error {
+	e := &service.Error{Kind: service.ErrValidation, Message: err.Error()}
+	var pe *paramError
+	if errors.As(err, &pe) {
+		e.Fields = []service.FieldError{{Field: pe.param, Message: pe.message}}
+	}
+	return e
+}
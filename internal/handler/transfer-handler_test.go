@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
+	"github.com/DinizJ/desafio/internal/service"
+)
+
+func TestParseCSVRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantRows  int
+		wantField string // erro do arquivo inteiro
+	}{
+		{name: "any column order", body: "priority,title\nhigh,Task\nlow,Other\n", wantRows: 2},
+		{name: "header with BOM", body: "\ufefftitle\nTask\n", wantRows: 1},
+		{name: "only header", body: "title\n"},
+		{name: "empty file", body: ""},
+		{name: "unknown column", body: "title,owner\nTask,ana\n", wantField: "columns"},
+		{name: "missing title", body: "status\npending\n", wantField: "columns"},
+		{name: "malformed", body: "title\n\"Task\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseCSVRows([]byte(tt.body))
+			var pe *paramError
+			switch {
+			case tt.wantField != "":
+				if !errors.As(err, &pe) || pe.param != tt.wantField {
+					t.Errorf("expected error on %s, got %v", tt.wantField, err)
+				}
+			case tt.name == "malformed":
+				if err == nil {
+					t.Error("expected error for malformed CSV")
+				}
+			case err != nil || len(rows) != tt.wantRows:
+				t.Errorf("expected %d rows, got %d (%v)", tt.wantRows, len(rows), err)
+			}
+		})
+	}
+}
+
+func TestTransferFormats_RoundTrip(t *testing.T) {
+	parentID := "3f2b8c1e-7a4d-4e5f-9b6a-1c2d3e4f5a6b"
+	dueAt := time.Date(2030, 1, 2, 15, 0, 0, 0, time.UTC)
+	task := model.Task{
+		ID: "9a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d", ParentID: &parentID, Title: "Comprar leite, pão",
+		Description: "linha 1\nlinha 2", Status: model.StatusInProgress, Priority: model.PriorityHigh,
+		DueAt: &dueAt, Tags: []string{"casa", "mercado"}, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+	want := service.UpdateTaskInput{
+		Title: task.Title, Description: task.Description, Status: task.Status, Priority: task.Priority,
+		DueAt: &dueAt, Tags: task.Tags, ParentID: parentID,
+	}
+
+	for _, format := range []string{formatCSV, formatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := newTaskEncoder(format, &buf)
+			if err := enc.begin(); err != nil {
+				t.Fatal(err)
+			}
+			if err := enc.encode(task); err != nil {
+				t.Fatal(err)
+			}
+			if err := enc.flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			// O que a exportação escreve a importação lê de volta
+			var rows []service.ImportRow
+			var err error
+			if format == formatCSV {
+				rows, err = parseCSVRows(buf.Bytes())
+			} else {
+				rows = parseJSONLRows(buf.Bytes())
+			}
+			if err != nil || len(rows) != 1 || rows[0].Err != nil {
+				t.Fatalf("expected one valid row, got %+v (%v)", rows, err)
+			}
+			got := rows[0]
+			if got.ID != task.ID || got.Task.Title != want.Title || got.Task.Description != want.Description ||
+				got.Task.Status != want.Status || got.Task.Priority != want.Priority || got.Task.ParentID != want.ParentID ||
+				got.Task.DueAt == nil || !got.Task.DueAt.Equal(dueAt) || len(got.Task.Tags) != 2 {
+				t.Errorf("expected %+v, got %+v", want, got.Task)
+			}
+		})
+	}
+
+	// Valor inválido só marca a linha, com o campo
+	rows := parseJSONLRows([]byte("{\"title\": \"ok\"}\n\n{\"title\": 1}\n"))
+	var vErr *service.Error
+	if len(rows) != 2 || rows[1].Line != 3 || !errors.As(rows[1].Err, &vErr) || vErr.Fields[0].Field != "title" {
+		t.Errorf("expected title error on line 3, got %+v", rows)
+	}
+}
+
+// Task atrasada exportada de um ambiente entra em outro vazio, com o mesmo prazo
+func TestTransfer_ExportOverdueImportIntoEmptyRepo(t *testing.T) {
+	ctx := context.Background()
+	dueAt := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+
+	source := repository.NewMemoryTaskRepository()
+	task := &model.Task{
+		ID: "3f2b8c1e-7a4d-4e5f-9b6a-1c2d3e4f5a6b", Title: "Late", Status: model.StatusPending,
+		Priority: model.PriorityHigh, DueAt: &dueAt, Version: 1, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+	if err := source.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, format := range []string{formatCSV, formatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewTaskHandler(service.NewTaskService(source, service.Config{})).
+				ExportTasks(rec, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/export?format="+format, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected 200 on export, got %d %s", rec.Code, rec.Body)
+			}
+
+			target := repository.NewMemoryTaskRepository()
+			for _, dryRun := range []string{"true", "false"} {
+				imported := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks/import?format="+format+"&dry_run="+dryRun, bytes.NewReader(rec.Body.Bytes()))
+				NewTaskHandler(service.NewTaskService(target, service.Config{})).ImportTasks(imported, req)
+
+				var resp struct {
+					Created int `json:"created"`
+					Failed  int `json:"failed"`
+				}
+				if err := json.NewDecoder(imported.Body).Decode(&resp); err != nil || resp.Created != 1 || resp.Failed != 0 {
+					t.Fatalf("dry_run=%s: expected the overdue task created, got %+v (%v)", dryRun, resp, err)
+				}
+			}
+
+			got, _ := target.FindByID(ctx, task.ID)
+			if got == nil || got.DueAt == nil || !got.DueAt.Equal(dueAt) {
+				t.Errorf("expected imported task with due_at %v, got %+v", dueAt, got)
+			}
+		})
+	}
+}
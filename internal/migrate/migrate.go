@@ -0,0 +1,359 @@
+// Package migrate aplica as migrations embutidas em migrations/ e registra
+// as versões aplicadas na tabela schema_migrations.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/sqlutil"
+	"github.com/DinizJ/desafio/migrations"
+)
+
+// ErrSchemaAhead indica que o banco tem migrations que este binário não conhece
+// (ex.: rodou uma versão mais nova do código). Subir assim pode corromper dados.
+var ErrSchemaAhead = errors.New("schema do banco está à frente do código")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status de uma migration: aplicada ou pendente
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	driver     string // "mysql", "postgres" ou "sqlite"
+	migrations []Migration
+}
+
+// New carrega as migrations embutidas do driver informado
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	dir, err := fs.Sub(migrations.FS, driver)
+	if err != nil {
+		return nil, fmt.Errorf("migrations do driver %q:%w", driver, err)
+	}
+
+	list, err := load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("nenhuma migration encontrada para o driver %q", driver)
+	}
+
+	return &Migrator{db: db, driver: driver, migrations: list}, nil
+}
+
+// load lê os pares NNN_nome.up.sql / NNN_nome.down.sql, ordenados por versão
+func load(dir fs.FS) ([]Migration, error) {
+	files, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar migrations:%w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		name := file.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		prefix, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("nome de migration inválido: %s", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("versão inválida na migration %s:%w", name, err)
+		}
+
+		content, err := fs.ReadFile(dir, name)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migration %s:%w", name, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("versão %d duplicada: %s e %s", version, m.Name, label)
+		}
+
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %03d_%s sem arquivo .up.sql", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// Latest é a maior versão conhecida pelo binário
+func (m *Migrator) Latest() int {
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// ------------------------UP--------------------------------
+// Up aplica todas as migrations pendentes, em ordem, e retorna as que foram aplicadas
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkAhead(applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+
+		err := m.run(ctx, mig.Up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx,
+				m.bind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+				mig.Version, mig.Name, time.Now().UTC(),
+			)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("erro ao aplicar migration %03d_%s:%w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+
+	return done, nil
+}
+
+// ------------------------DOWN--------------------------------
+// Down reverte a última migration aplicada. Retorna nil se não há nada para reverter.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkAhead(applied); err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+
+		if mig.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s não tem arquivo .down.sql", mig.Version, mig.Name)
+		}
+
+		err := m.run(ctx, mig.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, m.bind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro ao reverter migration %03d_%s:%w", mig.Version, mig.Name, err)
+		}
+		return &mig, nil
+	}
+
+	return nil, nil
+}
+
+// ------------------------STATUS--------------------------------
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = &at
+		}
+		list = append(list, st)
+	}
+
+	return list, m.checkAhead(applied)
+}
+
+// Check retorna quantas migrations estão pendentes e falha com ErrSchemaAhead
+// se o banco tem versões que o binário não conhece
+func (m *Migrator) Check(ctx context.Context) (pending int, err error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.checkAhead(applied); err != nil {
+		return 0, err
+	}
+
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) checkAhead(applied map[int]time.Time) error {
+	latest := m.Latest()
+	for version := range applied {
+		if version > latest {
+			return fmt.Errorf("%w: banco na versão %d, código conhece até a %d", ErrSchemaAhead, version, latest)
+		}
+	}
+	return nil
+}
+
+// appliedVersions cria a schema_migrations se necessário e lê as versões aplicadas
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int]time.Time, error) {
+	create := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at ` + m.timestampType() + ` NOT NULL
+		)`
+	if _, err := m.db.ExecContext(ctx, create); err != nil {
+		return nil, fmt.Errorf("erro ao criar schema_migrations:%w", err)
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations:%w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var (
+			version int
+			at      time.Time
+		)
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("erro ao ler schema_migrations:%w", err)
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations:%w", err)
+	}
+
+	return applied, nil
+}
+
+// run executa o script e o registro em schema_migrations na mesma transação.
+// PostgreSQL e SQLite desfazem tudo em caso de erro; no MySQL DDL faz commit implícito,
+// então um script que falha no meio pode precisar de ajuste manual.
+func (m *Migrator) run(ctx context.Context, script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%s:%w", firstLine(stmt), err)
+		}
+	}
+
+	if err := record(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) timestampType() string {
+	switch m.driver {
+	case "postgres":
+		return "TIMESTAMPTZ"
+	case "sqlite":
+		return "DATETIME"
+	default:
+		return "TIMESTAMP"
+	}
+}
+
+// bind troca "?" por "$n" no PostgreSQL
+func (m *Migrator) bind(query string) string {
+	if m.driver != "postgres" {
+		return query
+	}
+	return sqlutil.Rebind(query)
+}
+
+// splitStatements separa o script em comandos terminados por ";".
+// Linhas de comentário ("--") são descartadas. Os scripts do projeto não têm
+// ";" dentro de strings, então não é preciso um parser de SQL completo.
+func splitStatements(script string) []string {
+	var (
+		stmts   []string
+		current strings.Builder
+	)
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteByte('\n')
+
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+
+	return stmts
+}
+
+func firstLine(stmt string) string {
+	line, _, _ := strings.Cut(stmt, "\n")
+	return line
+}
@@ -0,0 +1,201 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+func newTestMigrator(t *testing.T) *Migrator {
+	t.Helper()
+
+	db, err := repository.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(db, "sqlite")
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	return m
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	m := newTestMigrator(t)
+	ctx := context.Background()
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != len(m.migrations) {
+		t.Fatalf("expected %d migrations applied, got %d", len(m.migrations), len(applied))
+	}
+
+	// Rodar de novo não faz nada
+	applied, err = m.Up(ctx)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("expected no-op second run, got %d applied, err %v", len(applied), err)
+	}
+
+	if pending, err := m.Check(ctx); err != nil || pending != 0 {
+		t.Fatalf("expected 0 pending, got %d, err %v", pending, err)
+	}
+
+	reverted, err := m.Down(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reverted == nil || reverted.Version != m.Latest() {
+		t.Fatalf("expected latest migration reverted, got %v", reverted)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := status[len(status)-1]
+	if last.Applied {
+		t.Errorf("expected migration %d pending after down", last.Version)
+	}
+	if !status[0].Applied || status[0].AppliedAt == nil {
+		t.Errorf("expected migration %d applied", status[0].Version)
+	}
+
+	if pending, _ := m.Check(ctx); pending != 1 {
+		t.Errorf("expected 1 pending, got %d", pending)
+	}
+}
+
+func TestMigrator_DownToEmpty(t *testing.T) {
+	m := newTestMigrator(t)
+	ctx := context.Background()
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Todos os .down.sql precisam funcionar
+	for range m.migrations {
+		if _, err := m.Down(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	reverted, err := m.Down(ctx)
+	if err != nil || reverted != nil {
+		t.Fatalf("expected nothing to revert, got %v, err %v", reverted, err)
+	}
+}
+
+// A 008 recria a tabela tasks no SQLite: tags e dependências precisam sobreviver
+// à ida e à volta, mesmo com foreign_keys (ON DELETE CASCADE) ligado
+func TestMigrator_LifecycleRebuildKeepsRelations(t *testing.T) {
+	m := newTestMigrator(t)
+	ctx := context.Background()
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	seed := []string{
+		"INSERT INTO tasks (id, title, status) VALUES ('a', 'A', 'pending'), ('b', 'B', 'cancelled')",
+		"INSERT INTO task_tags (task_id, tag) VALUES ('a', 'urgent')",
+		"INSERT INTO task_dependencies (task_id, blocker_id) VALUES ('a', 'b')",
+	}
+	for _, q := range seed {
+		if _, err := m.db.Exec(q); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	count := func(table string) int {
+		t.Helper()
+		var n int
+		if err := m.db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		return n
+	}
+
+	// Volta para antes da 008 (cancelled vira completed) e reaplica
+	for {
+		reverted, err := m.Down(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reverted.Name == "task_lifecycle" {
+			break
+		}
+	}
+
+	var status string
+	if err := m.db.QueryRow("SELECT status FROM tasks WHERE id = 'b'").Scan(&status); err != nil || status != "completed" {
+		t.Errorf("expected cancelled mapped to completed on down, got %q (%v)", status, err)
+	}
+	if count("task_tags") != 1 || count("task_dependencies") != 1 {
+		t.Fatalf("expected relations kept after down, got %d tags and %d dependencies",
+			count("task_tags"), count("task_dependencies"))
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count("task_tags") != 1 || count("task_dependencies") != 1 {
+		t.Fatalf("expected relations kept after up, got %d tags and %d dependencies",
+			count("task_tags"), count("task_dependencies"))
+	}
+
+	// O CHECK novo aceita os status do ciclo de vida e continua recusando os inválidos
+	if _, err := m.db.Exec("UPDATE tasks SET status = 'in_progress' WHERE id = 'a'"); err != nil {
+		t.Errorf("expected in_progress accepted, got %v", err)
+	}
+	if _, err := m.db.Exec("UPDATE tasks SET status = 'done' WHERE id = 'a'"); err == nil {
+		t.Error("expected invalid status rejected")
+	}
+}
+
+func TestMigrator_SchemaAhead(t *testing.T) {
+	m := newTestMigrator(t)
+	ctx := context.Background()
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Simula um binário mais novo que aplicou uma migration desconhecida
+	_, err := m.db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Latest()+1, "from_the_future", time.Now().UTC())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := m.Check(ctx); !errors.Is(err, ErrSchemaAhead) {
+		t.Errorf("expected ErrSchemaAhead from Check, got %v", err)
+	}
+	if _, err := m.Up(ctx); !errors.Is(err, ErrSchemaAhead) {
+		t.Errorf("expected ErrSchemaAhead from Up, got %v", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- comentário; com ponto e vírgula
+CREATE TABLE t (
+    id INT
+);
+
+CREATE INDEX idx ON t(id);
+`
+	stmts := splitStatements(script)
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %q", len(stmts), stmts)
+	}
+	if stmts[1] != "CREATE INDEX idx ON t(id);" {
+		t.Errorf("unexpected statement: %q", stmts[1])
+	}
+}
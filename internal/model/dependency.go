@@ -0,0 +1,26 @@
+package model
+
+// Dependency diz que TaskID está bloqueada por BlockerID:
+// TaskID só pode ser concluída depois de BlockerID
+type Dependency struct {
+	TaskID    string `json:"task_id"`
+	BlockerID string `json:"blocker_id"`
+}
+
+// TaskDependencies são as duas pontas do grafo a partir de uma task
+type TaskDependencies struct {
+	BlockedBy []Task `json:"blocked_by"` // tasks que bloqueiam esta
+	Blocking  []Task `json:"blocking"`   // tasks que esta bloqueia
+}
+
+// WorkItem é uma task em aberto no plano de trabalho (ordem topológica)
+type WorkItem struct {
+	Task
+	Ready     bool     `json:"ready"`      // nenhum bloqueio em aberto: dá para começar agora
+	BlockedBy []string `json:"blocked_by"` // ids dos bloqueios ainda em aberto
+}
+
+// WorkPlan é o envelope do plano de trabalho
+type WorkPlan struct {
+	Items []WorkItem `json:"items"`
+}
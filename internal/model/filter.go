@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// TaskFilter é o filtro estruturado das listagens, implementado por todos os repositories.
+// Campos vazios não filtram. Listas são "qualquer um de" (IN);
+// datas "After" são inclusivas e "Before" exclusivas.
+type TaskFilter struct {
+	Statuses   []string
+	Priorities []string
+
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+
+	DueAfter  *time.Time
+	DueBefore *time.Time
+
+	// Overdue true traz só as atrasadas, false só as que não estão atrasadas.
+	// O "agora" é Now; zero usa o relógio no momento da consulta.
+	Overdue *bool
+	Now     time.Time
+
+	// ParentID traz só as subtasks diretas dessa task
+	ParentID string
+
+	// Tags filtra por tag; TagMode diz se basta uma (TagMatchAny, o padrão) ou se precisa de todas
+	Tags    []string
+	TagMode string
+
+	// Busca por trecho, sem diferenciar maiúsculas de minúsculas
+	TitleContains       string
+	DescriptionContains string
+}
+
+// Modos do filtro de tags
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// Reference devolve o instante usado para calcular atraso
+func (f TaskFilter) Reference() time.Time {
+	if f.Now.IsZero() {
+		return time.Now()
+	}
+	return f.Now
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// IdempotencyRecord guarda a resposta de uma requisição feita com o header Idempotency-Key,
+// para que o reenvio da mesma requisição receba a mesma resposta em vez de repetir a operação
+type IdempotencyRecord struct {
+	Key         string    `db:"idempotency_key"`
+	Fingerprint string    `db:"fingerprint"` // SHA-256 do método, caminho e corpo da requisição original
+	Owner       string    `db:"owner"`       // id aleatório de quem reservou: só ele grava a resposta ou libera a chave
+	StatusCode  int       `db:"status_code"` // 0 enquanto a requisição original está em andamento
+	ContentType string    `db:"content_type"`
+	ETag        string    `db:"etag"`
+	Body        []byte    `db:"body"`
+	CreatedAt   time.Time `db:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at"` // depois disso a chave pode ser reutilizada
+}
+
+// Pending diz se a requisição original ainda não terminou
+func (r IdempotencyRecord) Pending() bool {
+	return r.StatusCode == 0
+}
@@ -0,0 +1,9 @@
+package model
+
+// TaskPage é o envelope das listagens paginadas.
+// NextCursor vem vazio (omitido) na última página.
+type TaskPage struct {
+	Items      []Task `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -0,0 +1,22 @@
+package model
+
+// SearchResult é uma task encontrada pela busca, com a relevância e os trechos destacados
+type SearchResult struct {
+	Task
+	Score     float64         `json:"score"`
+	Highlight SearchHighlight `json:"highlight"`
+}
+
+// SearchHighlight traz os trechos com os termos buscados entre <mark></mark>.
+// O texto é HTML escapado, então pode ser renderizado direto.
+type SearchHighlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// SearchPage é o mesmo envelope de TaskPage, para os resultados da busca
+type SearchPage struct {
+	Items      []SearchResult `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// TagCount é uma tag e em quantas tasks ativas ela aparece
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
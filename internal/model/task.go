@@ -3,21 +3,102 @@ package model
 import "time"
 
 type Task struct {
-	ID          string    `db:"id" json:"id"`
-	Title       string    `db:"title" json:"title"`
-	Description string    `db:"description" json:"description"`
-	Status      string    `db:"status" json:"status"`
-	Priority    string    `db:"priority" json:"priority"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
-	DeletedAt   time.Time `db:"deleted_at" json:"deleted_at"`
+	ID          string     `db:"id" json:"id"`
+	ParentID    *string    `db:"parent_id" json:"parent_id"` // task pai; null = task raiz
+	Title       string     `db:"title" json:"title"`
+	Description string     `db:"description" json:"description"`
+	Status      string     `db:"status" json:"status"`
+	Priority    string     `db:"priority" json:"priority"`
+	DueAt       *time.Time `db:"due_at" json:"due_at"`             // prazo opcional, null = sem prazo
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"` // gravado ao concluir, limpo ao reabrir
+	ReopenedAt  *time.Time `db:"reopened_at" json:"reopened_at"`   // última reabertura
+	ReopenedBy  *string    `db:"reopened_by" json:"reopened_by"`   // quem reabriu por último (header X-User)
+	Version     int64      `db:"version" json:"version"`           // incrementada a cada alteração; é o ETag
+	Tags        []string   `db:"-" json:"tags"`                    // tabela task_tags; normalizadas e em ordem alfabética
+	Overdue     bool       `db:"-" json:"overdue"`                 // calculado pelo service, não fica no banco
+	Progress    *int       `db:"-" json:"progress,omitempty"`      // % de subtasks encerradas; só em tasks com subtasks
+	Transitions []string   `db:"-" json:"available_transitions"`   // ações permitidas a partir do status atual
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt   *time.Time `db:"deleted_at" json:"deleted_at,omitempty"` // nil enquanto a task não está na lixeira
 }
 
 const (
-	StatusPending   = "pending"
-	StatusCompleted = "completed"
+	StatusPending    = "pending"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusCompleted  = "completed"
+	StatusCancelled  = "cancelled"
+	StatusArchived   = "archived"
 
 	PriorityLow    = "low"
 	PriorityMedium = "medium"
 	PriorityHigh   = "high"
 )
+
+// Statuses lista todos os status válidos, na ordem do ciclo de vida.
+// As transições permitidas entre eles ficam no service.
+var Statuses = []string{
+	StatusPending, StatusInProgress, StatusBlocked,
+	StatusCompleted, StatusCancelled, StatusArchived,
+}
+
+// Priorities lista as prioridades válidas, da menor para a maior
+var Priorities = []string{PriorityLow, PriorityMedium, PriorityHigh}
+
+// ClosedStatuses são os status em que a task não está mais em aberto:
+// task fechada nunca está atrasada nem aparece entre as próximas
+var ClosedStatuses = []string{StatusCompleted, StatusCancelled, StatusArchived}
+
+// IsClosed diz se o status é de task encerrada
+func IsClosed(status string) bool {
+	for _, s := range ClosedStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOverdue diz se a task passou do prazo em now sem ter sido encerrada
+func (t Task) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && t.DueAt.Before(now) && !IsClosed(t.Status)
+}
+
+// TaskPtrs dá acesso por ponteiro aos itens de uma lista, para quem preenche campos
+// das tasks no lugar (ex.: tags no repository, campos calculados no service)
+func TaskPtrs(tasks []Task) []*Task {
+	ptrs := make([]*Task, len(tasks))
+	for i := range tasks {
+		ptrs[i] = &tasks[i]
+	}
+	return ptrs
+}
+
+// ChildStats resume as subtasks ativas de uma task
+type ChildStats struct {
+	Total  int
+	Closed int
+}
+
+// Progress é a porcentagem (arredondada para baixo) de subtasks encerradas
+func (c ChildStats) Progress() int {
+	if c.Total == 0 {
+		return 0
+	}
+	return c.Closed * 100 / c.Total
+}
+
+// PriorityRank dá a ordem semântica da prioridade (low < medium < high).
+// Prioridade desconhecida fica abaixo de todas.
+func PriorityRank(priority string) int {
+	switch priority {
+	case PriorityLow:
+		return 1
+	case PriorityMedium:
+		return 2
+	case PriorityHigh:
+		return 3
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Grafo de dependências na tabela task_dependencies (task_id bloqueada por blocker_id).
+// Validações de existência e de ciclo ficam no service.
+
+//LockDependencies
+
+// LockDependencies trava a linha única de dependency_graph_lock (SELECT ... FOR UPDATE)
+func (r *TaskRepository) LockDependencies(ctx context.Context) error {
+	var id int
+	err := r.queryRow(ctx, "SELECT id FROM dependency_graph_lock WHERE id = 1"+r.dialect.forUpdate()).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("erro ao travar dependências:%w", err)
+	}
+	return nil
+}
+
+//AddDependency
+
+func (r *TaskRepository) AddDependency(ctx context.Context, taskID, blockerID string) error {
+	query := `
+		INSERT INTO task_dependencies (task_id, blocker_id, created_at) VALUES (?, ?, ?)`
+	_, err := r.exec(ctx, query, taskID, blockerID, utc(time.Now()))
+	if err != nil {
+		return fmt.Errorf("erro ao salvar dependência:%w", err)
+	}
+	return nil
+}
+
+//RemoveDependency
+
+func (r *TaskRepository) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	if !r.dialect.validID(taskID) || !r.dialect.validID(blockerID) {
+		return nil
+	}
+
+	query := `
+		DELETE FROM task_dependencies WHERE task_id = ? AND blocker_id = ?`
+	_, err := r.exec(ctx, query, taskID, blockerID)
+	if err != nil {
+		return fmt.Errorf("erro ao remover dependência:%w", err)
+	}
+	return nil
+}
+
+//FindBlockers
+
+// FindBlockers lista as tasks ativas que bloqueiam taskID
+func (r *TaskRepository) FindBlockers(ctx context.Context, taskID string) ([]model.Task, error) {
+	return r.findLinked(ctx, "SELECT blocker_id FROM task_dependencies WHERE task_id = ?", taskID)
+}
+
+//FindBlocking
+
+// FindBlocking lista as tasks ativas bloqueadas por taskID
+func (r *TaskRepository) FindBlocking(ctx context.Context, taskID string) ([]model.Task, error) {
+	return r.findLinked(ctx, "SELECT task_id FROM task_dependencies WHERE blocker_id = ?", taskID)
+}
+
+func (r *TaskRepository) findLinked(ctx context.Context, sub string, id string) ([]model.Task, error) {
+	if !r.dialect.validID(id) {
+		return nil, nil
+	}
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE deleted_at IS NULL AND id IN (` + sub + `)
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar dependências:%w", err)
+	}
+	defer rows.Close()
+
+	tasks, err := collectTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, r.loadTags(ctx, model.TaskPtrs(tasks))
+}
+
+//FindDependencies
+
+// FindDependencies devolve todas as arestas do grafo, inclusive as de tasks na lixeira:
+// uma task restaurada volta com as dependências dela, e a checagem de ciclo precisa enxergá-las
+func (r *TaskRepository) FindDependencies(ctx context.Context) ([]model.Dependency, error) {
+	rows, err := r.query(ctx, "SELECT task_id, blocker_id FROM task_dependencies")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar dependências:%w", err)
+	}
+	defer rows.Close()
+
+	var deps []model.Dependency
+	for rows.Next() {
+		var d model.Dependency
+		if err := rows.Scan(&d.TaskID, &d.BlockerID); err != nil {
+			return nil, fmt.Errorf("erro ao ler dependências:%w", err)
+		}
+		deps = append(deps, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao percorrer dependências:%w", err)
+	}
+
+	return deps, nil
+}
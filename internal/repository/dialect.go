@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+
+	"github.com/DinizJ/desafio/internal/sqlutil"
+)
+
+// dialect guarda as diferenças de SQL entre os bancos suportados.
+// As queries são escritas uma vez, no formato do MySQL (placeholders "?"),
+// e adaptadas aqui para cada banco.
+type dialect int
+
+const (
+	dialectMySQL dialect = iota
+	dialectSQLite
+	dialectPostgres
+)
+
+// rebind troca os placeholders "?" por "$1, $2, ..." no PostgreSQL
+func (d dialect) rebind(query string) string {
+	if d != dialectPostgres {
+		return query
+	}
+	return sqlutil.Rebind(query)
+}
+
+// onConflictDoNothing completa um INSERT para não gravar nada (nem falhar) quando
+// a chave primária já existe; quem chama confere pelo RowsAffected
+func (d dialect) onConflictDoNothing(key string) string {
+	if d == dialectMySQL {
+		return " ON DUPLICATE KEY UPDATE " + key + " = " + key
+	}
+	return " ON CONFLICT (" + key + ") DO NOTHING"
+}
+
+// forUpdate completa o SELECT de uma linha que vai ser alterada na mesma transação,
+// travando-a até o commit. No SQLite não há lock de linha: a conexão é uma só
+// (ver OpenSQLite) e a transação já serializa as escritas.
+func (d dialect) forUpdate() string {
+	if d == dialectSQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// validID diz se o id pode ser usado em uma query.
+// No PostgreSQL a coluna id é UUID e um texto qualquer gera erro de sintaxe
+// em vez de "não encontrado", então ids malformados são descartados antes.
+func (d dialect) validID(id string) bool {
+	if d != dialectPostgres {
+		return true
+	}
+	_, err := uuid.Parse(id)
+	return err == nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import "testing"
+
+func TestDialect_Rebind(t *testing.T) {
+	query := "UPDATE tasks SET title = ? WHERE id = ? AND deleted_at IS NULL"
+
+	tests := []struct {
+		name    string
+		dialect dialect
+		want    string
+	}{
+		{name: "mysql keeps ?", dialect: dialectMySQL, want: query},
+		{name: "sqlite keeps ?", dialect: dialectSQLite, want: query},
+		{name: "postgres numbers placeholders", dialect: dialectPostgres, want: "UPDATE tasks SET title = $1 WHERE id = $2 AND deleted_at IS NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.rebind(query); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDialect_ValidID(t *testing.T) {
+	if !dialectMySQL.validID("not-a-uuid") {
+		t.Error("expected mysql to accept any id")
+	}
+	if dialectPostgres.validID("not-a-uuid") {
+		t.Error("expected postgres to reject malformed uuid")
+	}
+	if !dialectPostgres.validID("6f1c2a9e-7d7b-4a53-9d0c-2f0f7f1b6b1e") {
+		t.Error("expected postgres to accept a valid uuid")
+	}
+}
+
+func TestDialect_ForUpdate(t *testing.T) {
+	if dialectMySQL.forUpdate() != " FOR UPDATE" || dialectPostgres.forUpdate() != " FOR UPDATE" {
+		t.Error("expected mysql and postgres to lock the row")
+	}
+	// SQLite não tem lock de linha: a transação na conexão única já serializa
+	if dialectSQLite.forUpdate() != "" {
+		t.Error("expected sqlite without FOR UPDATE")
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Respostas de requisições com Idempotency-Key, na tabela idempotency_keys.
+// A reserva é um INSERT que não faz nada se a chave já existe, então duas
+// requisições simultâneas com a mesma chave não executam a operação duas vezes.
+// Complete e Release filtram pelo owner da reserva, para uma requisição que passou
+// do prazo não mexer na chave que outra reservou depois.
+
+type IdempotencyRepository struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// Idempotency cria o IdempotencyStore no mesmo banco (e dialect) do repository de tasks
+func (r *TaskRepository) Idempotency() *IdempotencyRepository {
+	return &IdempotencyRepository{db: r.db, dialect: r.dialect}
+}
+
+//Reserve
+
+func (r *IdempotencyRepository) Reserve(ctx context.Context, record model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	// Chaves vencidas liberam o lugar e não acumulam na tabela
+	query := `
+		DELETE FROM idempotency_keys WHERE expires_at <= ?`
+	if _, err := r.db.ExecContext(ctx, r.dialect.rebind(query), utc(record.CreatedAt)); err != nil {
+		return nil, fmt.Errorf("erro ao remover chaves de idempotência vencidas:%w", err)
+	}
+
+	query = `
+		INSERT INTO idempotency_keys (idempotency_key, fingerprint, owner, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)` + r.dialect.onConflictDoNothing("idempotency_key")
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		record.Key,
+		record.Fingerprint,
+		record.Owner,
+		utc(record.CreatedAt),
+		utc(record.ExpiresAt),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reservar chave de idempotência:%w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao reservar chave de idempotência:%w", err)
+	}
+	if affected > 0 {
+		return nil, nil
+	}
+
+	query = `
+		SELECT idempotency_key, fingerprint, owner, status_code, content_type, etag, body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE idempotency_key = ?`
+
+	var existing model.IdempotencyRecord
+	err = r.db.QueryRowContext(ctx, r.dialect.rebind(query), record.Key).Scan(
+		&existing.Key,
+		&existing.Fingerprint,
+		&existing.Owner,
+		&existing.StatusCode,
+		&existing.ContentType,
+		&existing.ETag,
+		&existing.Body, // NULL vira nil
+		&existing.CreatedAt,
+		&existing.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Liberada entre o INSERT e o SELECT: o cliente pode tentar de novo
+		return nil, fmt.Errorf("chave de idempotência %q liberada durante a reserva", record.Key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar chave de idempotência:%w", err)
+	}
+	return &existing, nil
+}
+
+//Complete
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, record model.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = ?, content_type = ?, etag = ?, body = ?, expires_at = ?
+		WHERE idempotency_key = ? AND owner = ?`
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(query),
+		record.StatusCode,
+		record.ContentType,
+		record.ETag,
+		record.Body,
+		utc(record.ExpiresAt),
+		record.Key,
+		record.Owner,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar resposta idempotente:%w", err)
+	}
+	return reservationHeld(result)
+}
+
+//Release
+
+func (r *IdempotencyRepository) Release(ctx context.Context, key, owner string) error {
+	query := `
+		DELETE FROM idempotency_keys WHERE idempotency_key = ? AND owner = ?`
+	result, err := r.db.ExecContext(ctx, r.dialect.rebind(query), key, owner)
+	if err != nil {
+		return fmt.Errorf("erro ao liberar chave de idempotência:%w", err)
+	}
+	return reservationHeld(result)
+}
+
+// reservationHeld traduz 0 linhas afetadas por Complete/Release em ErrReservationLost
+func reservationHeld(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao conferir reserva de idempotência:%w", err)
+	}
+	if affected == 0 {
+		return ErrReservationLost
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/DinizJ/desafio/internal/model"
 )
@@ -10,13 +11,69 @@ import (
 // Agora o service pode receber qualquer implementação que satisfaça essa interface,
 // seja o repository real (com banco) ou um mock (para testes)
 
+// ErrVersionConflict é devolvido por Update quando a task mudou (ou saiu) desde que foi lida:
+// o Update só grava se task.Version ainda é a versão do banco, e em caso de sucesso a incrementa
+var ErrVersionConflict = errors.New("task version conflict")
+
+// ErrReservationLost é devolvido por Complete e Release quando a chave não está mais
+// reservada por record.Owner: a reserva venceu e foi apagada (ou tomada por outra requisição)
+var ErrReservationLost = errors.New("idempotency reservation lost")
+
 type TaskRepositoryInterface interface {
 	Save(ctx context.Context, task *model.Task) error
+	SaveIfAbsent(ctx context.Context, task *model.Task) (bool, error) // false = id já existe (inclusive na lixeira)
 	FindByID(ctx context.Context, id string) (*model.Task, error)
-	FindAll(ctx context.Context, status string) ([]model.Task, error)
+	// FindByIDForUpdate lê a task para alterá-la: dentro de WithinTx, outra transação que
+	// queira a mesma task espera o commit (SELECT ... FOR UPDATE)
+	FindByIDForUpdate(ctx context.Context, id string) (*model.Task, error)
+	FindAll(ctx context.Context, filter model.TaskFilter, opts ListOptions) ([]model.Task, error)
+	// Stream chama fn para cada task do filtro, em ordem de criação, sem carregar todas de uma vez
+	Stream(ctx context.Context, filter model.TaskFilter, fn func(model.Task) error) error
+	Search(ctx context.Context, text string, filter model.TaskFilter, opts SearchOptions) ([]model.SearchResult, error)
 	Update(ctx context.Context, task *model.Task) error
 	Delete(ctx context.Context, id string) error
+	TagCounts(ctx context.Context) ([]model.TagCount, error)
+	ChildStats(ctx context.Context, parentIDs []string) (map[string]model.ChildStats, error)
+
+	// Dependências (task_id bloqueada por blocker_id)
+	// LockDependencies trava o grafo inteiro até o fim da transação do WithinTx: quem vai
+	// alterar o grafo chama antes de lê-lo, e as alterações acontecem uma de cada vez
+	LockDependencies(ctx context.Context) error
+	AddDependency(ctx context.Context, taskID, blockerID string) error
+	RemoveDependency(ctx context.Context, taskID, blockerID string) error
+	FindBlockers(ctx context.Context, taskID string) ([]model.Task, error)
+	FindBlocking(ctx context.Context, taskID string) ([]model.Task, error)
+	FindDependencies(ctx context.Context) ([]model.Dependency, error)
+
+	// Lixeira (soft delete)
+	FindDeletedByID(ctx context.Context, id string) (*model.Task, error)
+	// FindDeletedByIDForUpdate é o FindByIDForUpdate das tasks na lixeira
+	FindDeletedByIDForUpdate(ctx context.Context, id string) (*model.Task, error)
+	FindDeleted(ctx context.Context) ([]model.Task, error)
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, id string) error
+
+	// WithinTx roda fn numa transação, com um repository preso a ela: ou tudo o que fn
+	// grava fica, ou (se fn devolver erro) nada fica. Dentro de fn, use só o repo recebido.
+	WithinTx(ctx context.Context, fn func(repo TaskRepositoryInterface) error) error
+}
+
+// IdempotencyStore guarda as respostas de requisições feitas com Idempotency-Key
+type IdempotencyStore interface {
+	// Reserve registra a chave como em andamento e devolve nil. Se ela já existe e não
+	// venceu, nada é gravado e o registro existente é devolvido.
+	Reserve(ctx context.Context, record model.IdempotencyRecord) (*model.IdempotencyRecord, error)
+	// Complete grava a resposta da requisição que reservou a chave, com o novo ExpiresAt
+	// (a reserva vence antes, para não prender a chave se a requisição nunca responder).
+	// Só vale se a chave ainda é de record.Owner; senão devolve ErrReservationLost.
+	Complete(ctx context.Context, record model.IdempotencyRecord) error
+	// Release apaga a chave, para que a requisição possa ser repetida (ex.: após falha interna).
+	// Como no Complete, só o dono apaga; senão devolve ErrReservationLost.
+	Release(ctx context.Context, key, owner string) error
 }
 
 // Verifica em tempo de compilação se TaskRepository implementa a interface
 var _ TaskRepositoryInterface = (*TaskRepository)(nil)
+var _ TaskRepositoryInterface = (*MemoryTaskRepository)(nil)
+var _ IdempotencyStore = (*IdempotencyRepository)(nil)
+var _ IdempotencyStore = (*MemoryIdempotencyStore)(nil)
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// SortField é um campo de ordenação. Name precisa estar em SortableFields.
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// DefaultSort é a ordem quando o cliente não pede nenhuma: criação, depois id.
+// O id sempre entra como último critério, então a ordem é determinística.
+var DefaultSort = []SortField{{Name: "created_at"}}
+
+// Cursor aponta para a última task da página anterior.
+// Values tem o valor de cada campo do sort (no formato de NewCursor)
+// e o ID desempata, então a próxima página começa logo depois dessa task.
+type Cursor struct {
+	Values []string
+	ID     string
+}
+
+// ListOptions controla ordenação e paginação do FindAll
+type ListOptions struct {
+	Sort  []SortField // vazio = DefaultSort
+	Limit int         // 0 = sem limite
+	After *Cursor     // nil = primeira página
+}
+
+func (o ListOptions) sortFields() []SortField {
+	if len(o.Sort) == 0 {
+		return DefaultSort
+	}
+	return o.Sort
+}
+
+// streamBatchSize é quantas tasks o Stream busca por vez
+const streamBatchSize = 500
+
+// streamTasks percorre as tasks do filtro página a página (keyset, na DefaultSort),
+// chamando fn para cada uma. Só uma página fica em memória, e a conexão fica livre
+// enquanto fn trabalha (no SQLite ela é uma só).
+func streamTasks(ctx context.Context, findAll func(context.Context, model.TaskFilter, ListOptions) ([]model.Task, error), filter model.TaskFilter, fn func(model.Task) error) error {
+	opts := ListOptions{Limit: streamBatchSize}
+	for {
+		tasks, err := findAll(ctx, filter, opts)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if err := fn(task); err != nil {
+				return err
+			}
+		}
+		if len(tasks) < streamBatchSize {
+			return nil
+		}
+		after := NewCursor(tasks[len(tasks)-1], nil)
+		opts.After = &after
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// MemoryIdempotencyStore é o IdempotencyStore do DB_DRIVER=memory: mesmas regras
+// do IdempotencyRepository, com as chaves num map protegido por mutex
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]model.IdempotencyRecord
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]model.IdempotencyRecord)}
+}
+
+func (m *MemoryIdempotencyStore) Reserve(ctx context.Context, record model.IdempotencyRecord) (*model.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, r := range m.records {
+		if !r.ExpiresAt.After(record.CreatedAt) {
+			delete(m.records, key)
+		}
+	}
+
+	if existing, ok := m.records[record.Key]; ok {
+		existing.Body = append([]byte(nil), existing.Body...)
+		return &existing, nil
+	}
+
+	record.StatusCode = 0
+	record.Body = nil
+	m.records[record.Key] = record
+	return nil, nil
+}
+
+func (m *MemoryIdempotencyStore) Complete(ctx context.Context, record model.IdempotencyRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.records[record.Key]
+	if !ok || current.Owner != record.Owner {
+		return ErrReservationLost
+	}
+	current.StatusCode = record.StatusCode
+	current.ContentType = record.ContentType
+	current.ETag = record.ETag
+	current.Body = append([]byte(nil), record.Body...)
+	current.ExpiresAt = record.ExpiresAt
+	m.records[record.Key] = current
+	return nil
+}
+
+func (m *MemoryIdempotencyStore) Release(ctx context.Context, key, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if current, ok := m.records[key]; !ok || current.Owner != owner {
+		return ErrReservationLost
+	}
+	delete(m.records, key)
+	return nil
+}
@@ -0,0 +1,641 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Implementação em memória do repository, para demos e testes sem MySQL.
+// É segura para uso concorrente: leituras usam RLock e escritas Lock.
+// Guarda e devolve cópias, então quem chama nunca altera o estado interno sem passar por Update.
+
+type MemoryTaskRepository struct {
+	*memoryState
+	undo *memoryUndo // só no repository entregue ao fn do WithinTx
+}
+
+// memoryState é o estado compartilhado entre o repository e as transações abertas nele
+type memoryState struct {
+	mu    sync.RWMutex
+	txMu  sync.RWMutex // WithinTx trava para escrita; fora dele, leituras e escritas esperam a transação
+	tasks map[string]model.Task
+	deps  map[model.Dependency]bool // equivalente à tabela task_dependencies
+}
+
+func NewMemoryTaskRepository() *MemoryTaskRepository {
+	return &MemoryTaskRepository{memoryState: &memoryState{
+		tasks: make(map[string]model.Task),
+		deps:  make(map[model.Dependency]bool),
+	}}
+}
+
+// rlock trava o estado para leitura. Fora de WithinTx espera a transação aberta terminar,
+// para não ler o que ela ainda pode desfazer.
+func (m *MemoryTaskRepository) rlock() (unlock func()) {
+	if m.undo == nil {
+		m.txMu.RLock()
+	}
+	m.mu.RLock()
+	return func() {
+		m.mu.RUnlock()
+		if m.undo == nil {
+			m.txMu.RUnlock()
+		}
+	}
+}
+
+// lock trava o estado para escrita. Fora de WithinTx espera a transação aberta terminar,
+// para não gravar por baixo dela.
+func (m *MemoryTaskRepository) lock() (unlock func()) {
+	if m.undo == nil {
+		m.txMu.Lock()
+	}
+	m.mu.Lock()
+	return func() {
+		m.mu.Unlock()
+		if m.undo == nil {
+			m.txMu.Unlock()
+		}
+	}
+}
+
+// cloneTask copia também os campos ponteiro, para não compartilhar memória com o chamador
+func cloneTask(task model.Task) model.Task {
+	if task.DeletedAt != nil {
+		deletedAt := *task.DeletedAt
+		task.DeletedAt = &deletedAt
+	}
+	if task.DueAt != nil {
+		dueAt := *task.DueAt
+		task.DueAt = &dueAt
+	}
+	if task.ParentID != nil {
+		parentID := *task.ParentID
+		task.ParentID = &parentID
+	}
+	if task.CompletedAt != nil {
+		completedAt := *task.CompletedAt
+		task.CompletedAt = &completedAt
+	}
+	if task.ReopenedAt != nil {
+		reopenedAt := *task.ReopenedAt
+		task.ReopenedAt = &reopenedAt
+	}
+	if task.ReopenedBy != nil {
+		reopenedBy := *task.ReopenedBy
+		task.ReopenedBy = &reopenedBy
+	}
+	// Sempre lista (nunca nil), como o loadTags do SQL
+	task.Tags = append([]string{}, task.Tags...)
+	return task
+}
+
+// Save put new task
+
+func (m *MemoryTaskRepository) Save(ctx context.Context, task *model.Task) error {
+	defer m.lock()()
+
+	m.touchTask(task.ID)
+	m.tasks[task.ID] = cloneTask(*task)
+	return nil
+}
+
+//SaveIfAbsent
+
+func (m *MemoryTaskRepository) SaveIfAbsent(ctx context.Context, task *model.Task) (bool, error) {
+	defer m.lock()()
+
+	if _, ok := m.tasks[task.ID]; ok {
+		return false, nil
+	}
+	m.touchTask(task.ID)
+	m.tasks[task.ID] = cloneTask(*task)
+	return true, nil
+}
+
+//FindByID
+
+// FindByID ignora tasks que estão na lixeira; retorna nil, nil se não encontrar (igual ao MySQL)
+func (m *MemoryTaskRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
+	defer m.rlock()()
+
+	task, ok := m.tasks[id]
+	if !ok || task.DeletedAt != nil {
+		return nil, nil
+	}
+
+	task = cloneTask(task)
+	return &task, nil
+}
+
+//FindByIDForUpdate
+
+// FindByIDForUpdate é o FindByID: aqui quem serializa as transações é o txMu do WithinTx
+func (m *MemoryTaskRepository) FindByIDForUpdate(ctx context.Context, id string) (*model.Task, error) {
+	return m.FindByID(ctx, id)
+}
+
+//FindDeletedByID
+
+func (m *MemoryTaskRepository) FindDeletedByID(ctx context.Context, id string) (*model.Task, error) {
+	defer m.rlock()()
+
+	task, ok := m.tasks[id]
+	if !ok || task.DeletedAt == nil {
+		return nil, nil
+	}
+
+	task = cloneTask(task)
+	return &task, nil
+}
+
+//FindDeletedByIDForUpdate
+
+func (m *MemoryTaskRepository) FindDeletedByIDForUpdate(ctx context.Context, id string) (*model.Task, error) {
+	return m.FindDeletedByID(ctx, id)
+}
+
+//Stream
+
+func (m *MemoryTaskRepository) Stream(ctx context.Context, filter model.TaskFilter, fn func(model.Task) error) error {
+	return streamTasks(ctx, m.FindAll, filter, fn)
+}
+
+//FindAll
+
+// FindAll ordena e pagina com as mesmas regras do SQL (ver sort.go)
+func (m *MemoryTaskRepository) FindAll(ctx context.Context, filter model.TaskFilter, opts ListOptions) ([]model.Task, error) {
+	sortFields := opts.sortFields()
+	if _, err := orderByClause(sortFields); err != nil {
+		return nil, err
+	}
+
+	var after []any
+	if opts.After != nil {
+		var err error
+		if after, err = opts.After.args(sortFields); err != nil {
+			return nil, err
+		}
+	}
+
+	defer m.rlock()()
+
+	var tasks []model.Task
+	for _, task := range m.tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		if !matchesFilter(task, filter) {
+			continue
+		}
+		if opts.After != nil && compareToCursor(task, sortFields, after, opts.After.ID) <= 0 {
+			continue
+		}
+		tasks = append(tasks, cloneTask(task))
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return compareTasks(tasks[i], tasks[j], sortFields) < 0
+	})
+
+	if opts.Limit > 0 && len(tasks) > opts.Limit {
+		tasks = tasks[:opts.Limit]
+	}
+
+	return tasks, nil
+}
+
+//Search
+
+// Search usa a mesma pontuação do fallback SQL (ver search.go)
+func (m *MemoryTaskRepository) Search(ctx context.Context, text string, filter model.TaskFilter, opts SearchOptions) ([]model.SearchResult, error) {
+	terms := SearchTerms(text)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	defer m.rlock()()
+
+	var results []model.SearchResult
+	for _, task := range m.tasks {
+		if task.DeletedAt != nil || !matchesFilter(task, filter) {
+			continue
+		}
+		if score := fallbackScore(task, terms); score > 0 {
+			results = append(results, model.SearchResult{Task: cloneTask(task), Score: score})
+		}
+	}
+
+	// score DESC, created_at DESC, id ASC — igual ao ORDER BY do SQL
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	if opts.Offset >= len(results) {
+		return nil, nil
+	}
+	results = results[opts.Offset:]
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	return results, nil
+}
+
+// matchesFilter aplica o model.TaskFilter com a mesma semântica do filterClause do SQL
+func matchesFilter(task model.Task, f model.TaskFilter) bool {
+	if f.ParentID != "" && (task.ParentID == nil || *task.ParentID != f.ParentID) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, task.Status) {
+		return false
+	}
+	if len(f.Priorities) > 0 && !slices.Contains(f.Priorities, task.Priority) {
+		return false
+	}
+
+	if f.CreatedAfter != nil && task.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !task.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.UpdatedAfter != nil && task.UpdatedAt.Before(*f.UpdatedAfter) {
+		return false
+	}
+	if f.UpdatedBefore != nil && !task.UpdatedAt.Before(*f.UpdatedBefore) {
+		return false
+	}
+
+	// Sem prazo não passa em nenhum filtro de prazo, como o NULL no SQL
+	if f.DueAfter != nil && (task.DueAt == nil || task.DueAt.Before(*f.DueAfter)) {
+		return false
+	}
+	if f.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.Overdue != nil && task.IsOverdue(f.Reference()) != *f.Overdue {
+		return false
+	}
+
+	if len(f.Tags) > 0 && !matchesTags(task.Tags, f.Tags, f.TagMode) {
+		return false
+	}
+
+	if f.TitleContains != "" && !containsFold(task.Title, f.TitleContains) {
+		return false
+	}
+	if f.DescriptionContains != "" && !containsFold(task.Description, f.DescriptionContains) {
+		return false
+	}
+
+	return true
+}
+
+// matchesTags: "all" exige todas as tags do filtro, qualquer outro modo basta uma
+func matchesTags(taskTags, filterTags []string, mode string) bool {
+	for _, tag := range filterTags {
+		has := slices.Contains(taskTags, tag)
+		if mode == model.TagMatchAll && !has {
+			return false
+		}
+		if mode != model.TagMatchAll && has {
+			return true
+		}
+	}
+	return mode == model.TagMatchAll
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+//FindDeleted
+
+// FindDeleted lista a lixeira, das deleções mais recentes para as mais antigas
+func (m *MemoryTaskRepository) FindDeleted(ctx context.Context) ([]model.Task, error) {
+	defer m.rlock()()
+
+	var tasks []model.Task
+	for _, task := range m.tasks {
+		if task.DeletedAt != nil {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+//Update
+
+// Update só altera os mesmos campos que o UPDATE do MySQL, inclusive a checagem de versão
+func (m *MemoryTaskRepository) Update(ctx context.Context, task *model.Task) error {
+	defer m.lock()()
+
+	current, ok := m.tasks[task.ID]
+	if !ok || current.DeletedAt != nil || current.Version != task.Version {
+		return ErrVersionConflict
+	}
+
+	current.Title = task.Title
+	current.Description = task.Description
+	current.Status = task.Status
+	current.Priority = task.Priority
+	clone := cloneTask(*task)
+	current.ParentID = clone.ParentID
+	current.DueAt = clone.DueAt
+	current.CompletedAt = clone.CompletedAt
+	current.ReopenedAt = clone.ReopenedAt
+	current.ReopenedBy = clone.ReopenedBy
+	current.Tags = clone.Tags
+	current.UpdatedAt = task.UpdatedAt
+	current.Version++
+	m.touchTask(task.ID)
+	m.tasks[task.ID] = current
+	task.Version = current.Version
+	return nil
+}
+
+//TagCounts
+
+// TagCounts conta as tags das tasks ativas, das mais usadas para as menos
+func (m *MemoryTaskRepository) TagCounts(ctx context.Context) ([]model.TagCount, error) {
+	defer m.rlock()()
+
+	byTag := map[string]int{}
+	for _, task := range m.tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		for _, tag := range task.Tags {
+			byTag[tag]++
+		}
+	}
+
+	var counts []model.TagCount
+	for tag, n := range byTag {
+		counts = append(counts, model.TagCount{Tag: tag, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+
+	return counts, nil
+}
+
+//Delete
+
+func (m *MemoryTaskRepository) Delete(ctx context.Context, id string) error {
+	defer m.lock()()
+
+	task, ok := m.tasks[id]
+	if !ok || task.DeletedAt != nil {
+		return ErrVersionConflict
+	}
+
+	now := time.Now()
+	task.DeletedAt = &now
+	task.Version++
+	m.touchTask(id)
+	m.tasks[id] = task
+	return nil
+}
+
+//Restore
+
+func (m *MemoryTaskRepository) Restore(ctx context.Context, id string) error {
+	defer m.lock()()
+
+	task, ok := m.tasks[id]
+	if !ok || task.DeletedAt == nil {
+		return ErrVersionConflict
+	}
+
+	task.DeletedAt = nil
+	task.Version++
+	m.touchTask(id)
+	m.tasks[id] = task
+	return nil
+}
+
+//Purge
+
+func (m *MemoryTaskRepository) Purge(ctx context.Context, id string) error {
+	defer m.lock()()
+
+	m.touchTask(id)
+	delete(m.tasks, id)
+
+	// Dependências somem junto, como o ON DELETE CASCADE
+	for d := range m.deps {
+		if d.TaskID == id || d.BlockerID == id {
+			m.touchDep(d)
+			delete(m.deps, d)
+		}
+	}
+
+	// Subtasks viram tasks raiz, como o ON DELETE SET NULL
+	for childID, task := range m.tasks {
+		if task.ParentID != nil && *task.ParentID == id {
+			task.ParentID = nil
+			m.touchTask(childID)
+			m.tasks[childID] = task
+		}
+	}
+	return nil
+}
+
+//ChildStats
+
+// ChildStats conta as subtasks ativas (total e encerradas) de cada task informada
+func (m *MemoryTaskRepository) ChildStats(ctx context.Context, parentIDs []string) (map[string]model.ChildStats, error) {
+	defer m.rlock()()
+
+	stats := map[string]model.ChildStats{}
+	for _, task := range m.tasks {
+		if task.DeletedAt != nil || task.ParentID == nil || !slices.Contains(parentIDs, *task.ParentID) {
+			continue
+		}
+		c := stats[*task.ParentID]
+		c.Total++
+		if model.IsClosed(task.Status) {
+			c.Closed++
+		}
+		stats[*task.ParentID] = c
+	}
+	return stats, nil
+}
+
+//LockDependencies
+
+// LockDependencies não faz nada: o txMu do WithinTx já serializa as transações
+func (m *MemoryTaskRepository) LockDependencies(ctx context.Context) error {
+	return nil
+}
+
+//AddDependency
+
+func (m *MemoryTaskRepository) AddDependency(ctx context.Context, taskID, blockerID string) error {
+	defer m.lock()()
+
+	d := model.Dependency{TaskID: taskID, BlockerID: blockerID}
+	m.touchDep(d)
+	m.deps[d] = true
+	return nil
+}
+
+//RemoveDependency
+
+func (m *MemoryTaskRepository) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	defer m.lock()()
+
+	d := model.Dependency{TaskID: taskID, BlockerID: blockerID}
+	m.touchDep(d)
+	delete(m.deps, d)
+	return nil
+}
+
+//FindBlockers
+
+func (m *MemoryTaskRepository) FindBlockers(ctx context.Context, taskID string) ([]model.Task, error) {
+	return m.findLinked(func(d model.Dependency) (string, bool) { return d.BlockerID, d.TaskID == taskID }), nil
+}
+
+//FindBlocking
+
+func (m *MemoryTaskRepository) FindBlocking(ctx context.Context, taskID string) ([]model.Task, error) {
+	return m.findLinked(func(d model.Dependency) (string, bool) { return d.TaskID, d.BlockerID == taskID }), nil
+}
+
+// findLinked lista as tasks ativas na outra ponta das arestas selecionadas, na ordem do SQL
+func (m *MemoryTaskRepository) findLinked(pick func(model.Dependency) (string, bool)) []model.Task {
+	defer m.rlock()()
+
+	var tasks []model.Task
+	for d := range m.deps {
+		id, ok := pick(d)
+		if !ok {
+			continue
+		}
+		if task, exists := m.tasks[id]; exists && task.DeletedAt == nil {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+
+	slices.SortFunc(tasks, func(a, b model.Task) int {
+		return compareTasks(a, b, DefaultSort)
+	})
+	return tasks
+}
+
+//FindDependencies
+
+func (m *MemoryTaskRepository) FindDependencies(ctx context.Context) ([]model.Dependency, error) {
+	defer m.rlock()()
+
+	deps := make([]model.Dependency, 0, len(m.deps))
+	for d := range m.deps {
+		deps = append(deps, d)
+	}
+	return deps, nil
+}
+
+//WithinTx
+
+// WithinTx roda fn com um repository que anota o valor anterior de cada task e dependência
+// que altera: se fn devolver erro, só essas voltam ao que eram. As transações são
+// serializadas entre si e com as operações de fora (txMu), o que aqui faz o papel do lock de linha do banco.
+func (m *MemoryTaskRepository) WithinTx(ctx context.Context, fn func(repo TaskRepositoryInterface) error) error {
+	if m.undo != nil {
+		return fn(m)
+	}
+
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	tx := &MemoryTaskRepository{memoryState: m.memoryState, undo: &memoryUndo{
+		tasks: map[string]*model.Task{},
+		deps:  map[model.Dependency]bool{},
+	}}
+	if err := fn(tx); err != nil {
+		m.mu.Lock()
+		tx.undo.rollback(m.memoryState)
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// memoryUndo guarda o estado de antes da transação, só do que ela alterou
+type memoryUndo struct {
+	tasks map[string]*model.Task    // nil = a task não existia
+	deps  map[model.Dependency]bool // se a dependência existia
+}
+
+// touchTask anota a task antes da primeira alteração dela na transação; chamado com mu travado
+func (m *MemoryTaskRepository) touchTask(id string) {
+	if m.undo == nil {
+		return
+	}
+	if _, ok := m.undo.tasks[id]; ok {
+		return
+	}
+	var before *model.Task
+	if task, ok := m.tasks[id]; ok {
+		task = cloneTask(task)
+		before = &task
+	}
+	m.undo.tasks[id] = before
+}
+
+// touchDep é o touchTask das dependências
+func (m *MemoryTaskRepository) touchDep(d model.Dependency) {
+	if m.undo == nil {
+		return
+	}
+	if _, ok := m.undo.deps[d]; ok {
+		return
+	}
+	m.undo.deps[d] = m.deps[d]
+}
+
+// rollback devolve as tasks e dependências alteradas ao valor anotado; chamado com mu travado
+func (u *memoryUndo) rollback(state *memoryState) {
+	for id, before := range u.tasks {
+		if before == nil {
+			delete(state.tasks, id)
+			continue
+		}
+		state.tasks[id] = *before
+	}
+	for d, existed := range u.deps {
+		if existed {
+			state.deps[d] = true
+			continue
+		}
+		delete(state.deps, d)
+	}
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+func TestMemoryRepository_FindAllFilterAndOrder(t *testing.T) {
+	repo := NewMemoryTaskRepository()
+	ctx := context.Background()
+	base := time.Now()
+
+	// Inseridas fora de ordem de propósito
+	tasks := []model.Task{
+		{ID: "c", Title: "C", Status: model.StatusPending, CreatedAt: base.Add(2 * time.Minute)},
+		{ID: "a", Title: "A", Status: model.StatusCompleted, CreatedAt: base},
+		{ID: "b", Title: "B", Status: model.StatusPending, CreatedAt: base},
+	}
+	for i := range tasks {
+		if err := repo.Save(ctx, &tasks[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter model.TaskFilter
+		want   []string
+	}{
+		{name: "all ordered by created_at then id", want: []string{"a", "b", "c"}},
+		{name: "only pending", filter: model.TaskFilter{Statuses: []string{model.StatusPending}}, want: []string{"b", "c"}},
+		{name: "no match", filter: model.TaskFilter{Statuses: []string{"archived"}}, want: nil},
+		{name: "title substring ignores case", filter: model.TaskFilter{TitleContains: "c"}, want: []string{"c"}},
+		{name: "created range", filter: model.TaskFilter{CreatedAfter: timePtr(base.Add(time.Minute))}, want: []string{"c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.FindAll(ctx, tt.filter, ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d tasks, got %d", len(tt.want), len(got))
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("position %d: expected %q, got %q", i, id, got[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryRepository_NotFoundAndCopies(t *testing.T) {
+	repo := NewMemoryTaskRepository()
+	ctx := context.Background()
+
+	// Igual ao MySQL: não encontrado é nil, nil
+	task, err := repo.FindByID(ctx, "missing")
+	if task != nil || err != nil {
+		t.Fatalf("expected nil, nil; got %v, %v", task, err)
+	}
+
+	original := &model.Task{ID: "1", Title: "Original", Status: model.StatusPending}
+	if err := repo.Save(ctx, original); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Alterar o que foi salvo ou lido não pode mudar o estado interno
+	original.Title = "changed after save"
+	found, _ := repo.FindByID(ctx, "1")
+	found.Title = "changed after find"
+
+	again, _ := repo.FindByID(ctx, "1")
+	if again.Title != "Original" {
+		t.Errorf("expected stored title to be unchanged, got %q", again.Title)
+	}
+}
+
+func TestMemoryRepository_SoftDelete(t *testing.T) {
+	repo := NewMemoryTaskRepository()
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, &model.Task{ID: "1", Title: "Task", Version: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Delete(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Delete(ctx, "1"); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict deleting twice, got %v", err)
+	}
+
+	if task, _ := repo.FindByID(ctx, "1"); task != nil {
+		t.Error("expected deleted task to be hidden from FindByID")
+	}
+	if task, _ := repo.FindDeletedByID(ctx, "1"); task == nil || task.DeletedAt == nil || task.Version != 2 {
+		t.Errorf("expected deleted task in trash with deleted_at set and version 2, got %+v", task)
+	}
+
+	if err := repo.Restore(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task, _ := repo.FindByID(ctx, "1"); task == nil || task.Version != 3 {
+		t.Errorf("expected restored task visible with version 3, got %+v", task)
+	}
+}
+
+// Rode com -race para validar o uso concorrente
+func TestMemoryRepository_Concurrent(t *testing.T) {
+	repo := NewMemoryTaskRepository()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			task := &model.Task{ID: fmt.Sprintf("task-%d", i), Title: "Task", Status: model.StatusPending}
+			_ = repo.Save(ctx, task)
+			task.Status = model.StatusCompleted
+			_ = repo.Update(ctx, task)
+			_, _ = repo.FindAll(ctx, model.TaskFilter{}, ListOptions{})
+		}(i)
+	}
+	wg.Wait()
+
+	tasks, err := repo.FindAll(ctx, model.TaskFilter{Statuses: []string{model.StatusCompleted}}, ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 50 {
+		t.Errorf("expected 50 completed tasks, got %d", len(tasks))
+	}
+}
+
+// O rollback de uma transação desfaz só o que ela gravou, não o que foi gravado fora dela
+func TestMemoryRepository_RollbackKeepsConcurrentWrites(t *testing.T) {
+	repo := NewMemoryTaskRepository()
+	ctx := context.Background()
+	errFail := errors.New("fail")
+
+	var wg sync.WaitGroup
+	var seen *model.Task
+	err := repo.WithinTx(ctx, func(tx TaskRepositoryInterface) error {
+		if err := tx.Save(ctx, &model.Task{ID: "inside", Title: "Inside"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = repo.Save(ctx, &model.Task{ID: "outside", Title: "Outside"})
+		}()
+		go func() {
+			defer wg.Done()
+			seen, _ = repo.FindByID(ctx, "inside")
+		}()
+
+		// Dá tempo para as operações de fora chegarem enquanto a transação está aberta
+		time.Sleep(20 * time.Millisecond)
+		return errFail
+	})
+	if !errors.Is(err, errFail) {
+		t.Fatalf("expected the transaction error, got %v", err)
+	}
+	wg.Wait()
+
+	if task, _ := repo.FindByID(ctx, "outside"); task == nil {
+		t.Error("expected task saved outside the transaction to survive the rollback")
+	}
+	if task, _ := repo.FindByID(ctx, "inside"); task != nil {
+		t.Error("expected task saved inside the transaction to be rolled back")
+	}
+	if seen != nil {
+		t.Error("expected read outside the transaction not to see its uncommitted write")
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"database/sql"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registra o driver "pgx" no database/sql
+)
+
+// PostgreSQL usa o mesmo TaskRepository; o dialect converte os placeholders
+// para "$n" e o schema fica em migrations/postgres (UUID, timestamptz e CHECK).
+
+// NewPostgresTaskRepository cria o repository sobre uma conexão aberta com o driver "pgx"
+func NewPostgresTaskRepository(db *sql.DB) *TaskRepository {
+	return &TaskRepository{
+		db:      db,
+		dialect: dialectPostgres,
+	}
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Busca textual em título e descrição.
+// No MySQL usa o índice FULLTEXT (migration 003) com MATCH ... AGAINST.
+// Nos outros bancos (e em memória) cai para um LIKE por termo, com pontuação simples:
+// cada termo vale 2 se aparece no título e 1 se aparece na descrição.
+
+// SearchOptions controla a paginação da busca. A relevância não é uma chave
+// estável para keyset, então aqui a paginação é por offset.
+type SearchOptions struct {
+	Limit  int
+	Offset int
+}
+
+// maxSearchTerms limita o tamanho da query gerada no fallback
+const maxSearchTerms = 10
+
+// SearchTerms quebra o texto em termos em minúsculas, sem repetição.
+// Pontuação separa termos, então "bug-fix" vira "bug" e "fix".
+func SearchTerms(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		terms = append(terms, f)
+		if len(terms) == maxSearchTerms {
+			break
+		}
+	}
+	return terms
+}
+
+//Search
+
+func (r *TaskRepository) Search(ctx context.Context, text string, filter model.TaskFilter, opts SearchOptions) ([]model.SearchResult, error) {
+	var (
+		scoreExpr, matchExpr string
+		scoreArgs, matchArgs []any
+	)
+
+	if r.dialect == dialectMySQL {
+		scoreExpr = "MATCH(title, description) AGAINST (? IN NATURAL LANGUAGE MODE)"
+		matchExpr = scoreExpr + " > 0"
+		scoreArgs = []any{text}
+		matchArgs = []any{text}
+	} else {
+		terms := SearchTerms(text)
+		if len(terms) == 0 {
+			return nil, nil
+		}
+
+		var scores, matches []string
+		for _, term := range terms {
+			pattern := "%" + escapeLike(term) + "%"
+			scores = append(scores,
+				"(CASE WHEN LOWER(title) LIKE ? ESCAPE '!' THEN 2 ELSE 0 END)",
+				"(CASE WHEN LOWER(COALESCE(description, '')) LIKE ? ESCAPE '!' THEN 1 ELSE 0 END)",
+			)
+			matches = append(matches,
+				"LOWER(title) LIKE ? ESCAPE '!'",
+				"LOWER(COALESCE(description, '')) LIKE ? ESCAPE '!'",
+			)
+			scoreArgs = append(scoreArgs, pattern, pattern)
+			matchArgs = append(matchArgs, pattern, pattern)
+		}
+		scoreExpr = "(" + strings.Join(scores, " + ") + ")"
+		matchExpr = "(" + strings.Join(matches, " OR ") + ")"
+	}
+
+	where, filterArgs := filterClause(filter)
+
+	// Mesma ordem dos "?" na query: SELECT, WHERE da busca, filtros
+	args := append(append(scoreArgs, matchArgs...), filterArgs...)
+
+	query := `
+		SELECT ` + taskColumns + `, ` + scoreExpr + ` AS score
+		FROM tasks
+		WHERE deleted_at IS NULL AND ` + matchExpr + where + `
+		ORDER BY score DESC, created_at DESC, id ASC
+	`
+	if opts.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		if opts.Limit <= 0 {
+			return nil, fmt.Errorf("offset sem limit na busca")
+		}
+		query += " OFFSET " + strconv.Itoa(opts.Offset)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar busca de tasks:%w", err)
+	}
+	defer rows.Close()
+
+	var results []model.SearchResult
+	for rows.Next() {
+		var score float64
+		task, err := scanTask(rows, &score)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler resultado da busca:%w", err)
+		}
+		results = append(results, model.SearchResult{Task: task, Score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao percorrer resultados da busca:%w", err)
+	}
+
+	tasks := make([]*model.Task, len(results))
+	for i := range results {
+		tasks[i] = &results[i].Task
+	}
+	if err := r.loadTags(ctx, tasks); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// fallbackScore é a mesma pontuação do LIKE do SQL, usada pelo repository em memória
+func fallbackScore(task model.Task, terms []string) float64 {
+	title := strings.ToLower(task.Title)
+	description := strings.ToLower(task.Description)
+
+	var score float64
+	for _, term := range terms {
+		if strings.Contains(title, term) {
+			score += 2
+		}
+		if strings.Contains(description, term) {
+			score++
+		}
+	}
+	return score
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Whitelist de ordenação: o nome que vem do cliente só é usado para achar
+// a entrada aqui, e o SQL usa a expressão fixa da entrada. Nada do input vai para a query.
+
+type sortKind int
+
+const (
+	sortTime sortKind = iota
+	sortInt
+	sortString
+)
+
+type sortSpec struct {
+	expr     string               // expressão SQL do ORDER BY / keyset
+	kind     sortKind             // tipo do valor, para o cursor
+	nullable bool                 // NULLs ficam sempre no fim, em qualquer direção
+	value    func(model.Task) any // valor da task (time.Time, int ou string; nil se NULL)
+}
+
+// priorityRankSQL ordena pela semântica (low < medium < high), não pelo alfabeto
+const priorityRankSQL = `(CASE priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 ELSE 0 END)`
+
+var sortSpecs = map[string]sortSpec{
+	"created_at": {expr: "created_at", kind: sortTime, value: func(t model.Task) any { return t.CreatedAt }},
+	"updated_at": {expr: "updated_at", kind: sortTime, value: func(t model.Task) any { return t.UpdatedAt }},
+	"due_at": {expr: "due_at", kind: sortTime, nullable: true, value: func(t model.Task) any {
+		if t.DueAt == nil {
+			return nil
+		}
+		return *t.DueAt
+	}},
+	"priority": {expr: priorityRankSQL, kind: sortInt, value: func(t model.Task) any { return model.PriorityRank(t.Priority) }},
+	// LOWER para a ordem não depender da collation de cada banco
+	"title": {expr: "LOWER(title)", kind: sortString, value: func(t model.Task) any { return strings.ToLower(t.Title) }},
+}
+
+// IsSortable diz se o campo pode ser usado no sort
+func IsSortable(name string) bool {
+	_, ok := sortSpecs[name]
+	return ok
+}
+
+// NewCursor cria o cursor que aponta para depois da task, na ordem do sort.
+// Valor NULL vira string vazia.
+func NewCursor(task model.Task, sort []SortField) Cursor {
+	if len(sort) == 0 {
+		sort = DefaultSort
+	}
+
+	c := Cursor{ID: task.ID, Values: make([]string, len(sort))}
+	for i, f := range sort {
+		switch v := sortSpecs[f.Name].value(task).(type) {
+		case time.Time:
+			c.Values[i] = v.UTC().Format(time.RFC3339Nano)
+		case int:
+			c.Values[i] = strconv.Itoa(v)
+		case string:
+			c.Values[i] = v
+		}
+	}
+	return c
+}
+
+// Validate confere se o cursor é compatível com o sort (mesmo número de valores, tipos corretos)
+func (c Cursor) Validate(sort []SortField) error {
+	_, err := c.args(sort)
+	return err
+}
+
+// args converte os valores do cursor para os tipos de cada campo do sort (nil = NULL)
+func (c Cursor) args(sort []SortField) ([]any, error) {
+	if len(sort) == 0 {
+		sort = DefaultSort
+	}
+	if len(c.Values) != len(sort) || c.ID == "" {
+		return nil, fmt.Errorf("cursor não corresponde à ordenação")
+	}
+
+	args := make([]any, len(sort))
+	for i, f := range sort {
+		spec, ok := sortSpecs[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("campo de ordenação inválido: %q", f.Name)
+		}
+
+		raw := c.Values[i]
+		if spec.nullable && raw == "" {
+			args[i] = nil
+			continue
+		}
+
+		switch spec.kind {
+		case sortTime:
+			t, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return nil, fmt.Errorf("valor de cursor inválido para %s", f.Name)
+			}
+			args[i] = t
+		case sortInt:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("valor de cursor inválido para %s", f.Name)
+			}
+			args[i] = n
+		default:
+			args[i] = raw
+		}
+	}
+	return args, nil
+}
+
+// orderByClause monta o ORDER BY, sempre terminando no id.
+// Campos que aceitam NULL ganham antes um critério que joga os NULLs para o fim.
+func orderByClause(sort []SortField) (string, error) {
+	parts := make([]string, 0, len(sort)+1)
+	for _, f := range sort {
+		spec, ok := sortSpecs[f.Name]
+		if !ok {
+			return "", fmt.Errorf("campo de ordenação inválido: %q", f.Name)
+		}
+		dir := "ASC"
+		if f.Desc {
+			dir = "DESC"
+		}
+		if spec.nullable {
+			parts = append(parts, "(CASE WHEN "+spec.expr+" IS NULL THEN 1 ELSE 0 END) ASC")
+		}
+		parts = append(parts, spec.expr+" "+dir)
+	}
+	parts = append(parts, "id ASC")
+	return " ORDER BY " + strings.Join(parts, ", "), nil
+}
+
+// keysetClause gera a condição "vem depois do cursor", campo a campo:
+//
+//	depois(i) = (campo > v) OR (campo = v AND depois(i+1))   -- "<" nos campos DESC
+//	depois(n) = id > cursor.ID
+//
+// Em campos com NULL no fim: se o cursor está num NULL, só vêm os NULLs seguintes;
+// se não, os NULLs vêm todos depois.
+func keysetClause(sort []SortField, cursor Cursor) (string, []any, error) {
+	values, err := cursor.args(sort)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var args []any
+
+	var after func(i int) string
+	after = func(i int) string {
+		if i == len(sort) {
+			args = append(args, cursor.ID)
+			return "id > ?"
+		}
+
+		spec := sortSpecs[sort[i].Name]
+		value := values[i]
+		if t, ok := value.(time.Time); ok {
+			value = utc(t)
+		}
+
+		if value == nil {
+			return "(" + spec.expr + " IS NULL AND " + after(i+1) + ")"
+		}
+
+		op := ">"
+		if sort[i].Desc {
+			op = "<"
+		}
+
+		var ors []string
+		if spec.nullable {
+			ors = append(ors, spec.expr+" IS NULL")
+		}
+		ors = append(ors, spec.expr+" "+op+" ?")
+		args = append(args, value)
+
+		eq := spec.expr + " = ?"
+		args = append(args, value)
+		ors = append(ors, "("+eq+" AND "+after(i+1)+")")
+
+		return "(" + strings.Join(ors, " OR ") + ")"
+	}
+
+	clause := after(0)
+	return " AND " + clause, args, nil
+}
+
+// compareTasks compara duas tasks na ordem do sort (com id no final), como o ORDER BY faz
+func compareTasks(a, b model.Task, sort []SortField) int {
+	for _, f := range sort {
+		spec := sortSpecs[f.Name]
+		if c := compareField(spec.value(a), spec.value(b), f.Desc); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// compareToCursor compara a task com a posição do cursor (valores já convertidos por args)
+func compareToCursor(task model.Task, sort []SortField, values []any, id string) int {
+	for i, f := range sort {
+		if c := compareField(sortSpecs[f.Name].value(task), values[i], f.Desc); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(task.ID, id)
+}
+
+// compareField compara dois valores de um campo; NULL (nil) fica no fim nas duas direções
+func compareField(a, b any, desc bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	c := compareValues(a, b)
+	if desc {
+		c = -c
+	}
+	return c
+}
+
+func compareValues(a, b any) int {
+	switch av := a.(type) {
+	case time.Time:
+		return av.Compare(b.(time.Time))
+	case int:
+		return cmp.Compare(av, b.(int))
+	case string:
+		return cmp.Compare(av, b.(string))
+	}
+	return 0
+}
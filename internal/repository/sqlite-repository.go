@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite" // driver puro Go, não precisa de CGO
+)
+
+// SQLite como alternativa ao MySQL para desenvolvimento e CI.
+// As queries do TaskRepository são SQL padrão com placeholders "?",
+// então a mesma implementação serve para os dois bancos; o que muda é
+// a conexão (OpenSQLite) e o schema (migrations/sqlite).
+
+// NewSQLiteTaskRepository cria o repository sobre um banco aberto com OpenSQLite
+func NewSQLiteTaskRepository(db *sql.DB) *TaskRepository {
+	return &TaskRepository{
+		db:      db,
+		dialect: dialectSQLite,
+	}
+}
+
+// OpenSQLite abre (ou cria) o arquivo do banco. path ":memory:" cria um banco em memória.
+func OpenSQLite(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	// Datas em texto no formato do SQLite, que ordena corretamente como string
+	params.Set("_time_format", "sqlite")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir sqlite:%w", err)
+	}
+
+	// SQLite aceita um escritor por vez; com uma conexão só evitamos SQLITE_BUSY
+	// e, no caso de ":memory:", todas as queries enxergam o mesmo banco.
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
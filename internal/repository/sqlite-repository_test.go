@@ -0,0 +1,770 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DinizJ/desafio/internal/migrate"
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// newSQLiteTestRepo cria um banco SQLite em memória com o schema de migrations/sqlite.
+// Como o TaskRepository é o mesmo do MySQL, estes testes cobrem as queries SQL.
+func newSQLiteTestRepo(t *testing.T) *TaskRepository {
+	t.Helper()
+
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, "sqlite")
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+
+	return NewSQLiteTaskRepository(db)
+}
+
+func TestSQLiteRepository_TimestampsRoundTrip(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+
+	// Fuso diferente de UTC de propósito
+	loc := time.FixedZone("BRT", -3*60*60)
+	created := time.Date(2026, 2, 3, 10, 0, 0, 123456789, loc)
+
+	task := &model.Task{
+		ID:          "1",
+		Title:       "Round trip",
+		Description: "desc",
+		Status:      model.StatusPending,
+		Priority:    model.PriorityHigh,
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+	if err := repo.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil {
+		t.Fatal("expected task, got nil")
+	}
+	if !got.CreatedAt.Equal(created) || !got.UpdatedAt.Equal(created) {
+		t.Errorf("expected timestamps %v, got %v / %v", created, got.CreatedAt, got.UpdatedAt)
+	}
+	if got.DeletedAt != nil {
+		t.Errorf("expected nil deleted_at, got %v", got.DeletedAt)
+	}
+	if got.Title != task.Title || got.Priority != task.Priority {
+		t.Errorf("unexpected task: %+v", got)
+	}
+	if got.CompletedAt != nil || got.ReopenedAt != nil || got.ReopenedBy != nil {
+		t.Errorf("expected nil completion fields, got %+v", got)
+	}
+
+	// Campos de conclusão/reabertura gravados pelo Update
+	by := "ana"
+	task.CompletedAt = &created
+	task.ReopenedAt = &created
+	task.ReopenedBy = &by
+	if err := repo.Update(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err = repo.FindByID(ctx, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.CompletedAt == nil || !got.CompletedAt.Equal(created) {
+		t.Errorf("expected completed_at %v, got %v", created, got.CompletedAt)
+	}
+	if got.ReopenedAt == nil || !got.ReopenedAt.Equal(created) || got.ReopenedBy == nil || *got.ReopenedBy != by {
+		t.Errorf("expected reopen fields round trip, got %v / %v", got.ReopenedAt, got.ReopenedBy)
+	}
+}
+
+func TestSQLiteRepository_Constraints(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		task model.Task
+	}{
+		{name: "invalid status", task: model.Task{ID: "1", Title: "T", Status: "done", Priority: model.PriorityLow}},
+		{name: "invalid priority", task: model.Task{ID: "2", Title: "T", Status: model.StatusPending, Priority: "urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.Save(ctx, &tt.task); err == nil {
+				t.Error("expected constraint error, got nil")
+			}
+		})
+	}
+
+	// Todos os status do ciclo de vida passam pelo CHECK
+	now := time.Now()
+	for _, status := range model.Statuses {
+		task := model.Task{ID: status, Title: "T", Status: status, Priority: model.PriorityLow, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Save(ctx, &task); err != nil {
+			t.Errorf("status %q: unexpected error: %v", status, err)
+		}
+	}
+}
+
+func TestSQLiteRepository_SoftDeleteAndPurge(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	for _, id := range []string{"1", "2"} {
+		task := &model.Task{ID: id, Title: "Task " + id, Status: model.StatusPending, Priority: model.PriorityMedium, Version: 1, CreatedAt: now, UpdatedAt: now}
+		if err := repo.Save(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := repo.Delete(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tasks, err := repo.FindAll(ctx, model.TaskFilter{}, ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "2" {
+		t.Errorf("expected only task 2 listed, got %v", tasks)
+	}
+
+	trash, err := repo.FindDeleted(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trash) != 1 || trash[0].DeletedAt == nil || trash[0].Version != 2 {
+		t.Fatalf("expected task 1 in trash with deleted_at and version 2, got %v", trash)
+	}
+
+	if err := repo.Restore(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task, _ := repo.FindByID(ctx, "1"); task == nil || task.Version != 3 {
+		t.Fatalf("expected restored task with version 3, got %+v", task)
+	}
+	if err := repo.Restore(ctx, "1"); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict restoring an active task, got %v", err)
+	}
+
+	if err := repo.Purge(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task, _ := repo.FindDeletedByID(ctx, "1"); task != nil {
+		t.Error("expected purged task to be gone")
+	}
+}
+
+func TestSQLiteRepository_UpdateChecksVersion(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	task := &model.Task{ID: "1", Title: "Task", Status: model.StatusPending, Priority: model.PriorityMedium, Version: 1, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Save(ctx, task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Duas leituras da mesma versão: só a primeira escrita vale
+	first, _ := repo.FindByID(ctx, "1")
+	second, _ := repo.FindByID(ctx, "1")
+
+	first.Title = "First"
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Version != 2 {
+		t.Errorf("expected version 2 after update, got %d", first.Version)
+	}
+
+	second.Title = "Second"
+	if err := repo.Update(ctx, second); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+
+	got, _ := repo.FindByID(ctx, "1")
+	if got.Title != "First" || got.Version != 2 {
+		t.Errorf("expected first write kept at version 2, got %q v%d", got.Title, got.Version)
+	}
+
+	// Task na lixeira não é alterada
+	if err := repo.Delete(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Update(ctx, got); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("expected ErrVersionConflict for deleted task, got %v", err)
+	}
+}
+
+func TestSQLiteRepository_SaveIfAbsent(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	task := &model.Task{ID: "1", Title: "Task", Status: model.StatusPending, Priority: model.PriorityMedium, Version: 1, Tags: []string{"a"}, CreatedAt: now, UpdatedAt: now}
+	if created, err := repo.SaveIfAbsent(ctx, task); err != nil || !created {
+		t.Fatalf("expected task created, got %v (%v)", created, err)
+	}
+
+	// Id existente (ativo ou na lixeira) não é sobrescrito
+	other := *task
+	other.Title = "Other"
+	if created, err := repo.SaveIfAbsent(ctx, &other); err != nil || created {
+		t.Fatalf("expected existing id kept, got %v (%v)", created, err)
+	}
+	if err := repo.Delete(ctx, "1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created, err := repo.SaveIfAbsent(ctx, &other); err != nil || created {
+		t.Fatalf("expected deleted id kept, got %v (%v)", created, err)
+	}
+
+	got, _ := repo.FindDeletedByID(ctx, "1")
+	if got == nil || got.Title != "Task" || len(got.Tags) != 1 {
+		t.Errorf("expected original task, got %+v", got)
+	}
+}
+
+func TestSQLiteRepository_WithinTx(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	now := time.Now()
+	newTask := func(id string) *model.Task {
+		return &model.Task{ID: id, Title: "Task " + id, Status: model.StatusPending, Priority: model.PriorityMedium, Version: 1, Tags: []string{"a"}, CreatedAt: now, UpdatedAt: now}
+	}
+
+	// Erro no fn: nada do que foi gravado fica, nem as tags
+	boom := errors.New("boom")
+	err := repo.WithinTx(ctx, func(tx TaskRepositoryInterface) error {
+		if err := tx.Save(ctx, newTask("1")); err != nil {
+			return err
+		}
+		// WithinTx aninhado usa a mesma transação (o SQLite tem uma conexão só)
+		return tx.WithinTx(ctx, func(tx TaskRepositoryInterface) error {
+			if err := tx.Save(ctx, newTask("2")); err != nil {
+				return err
+			}
+			return boom
+		})
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	if got, _ := repo.FindByID(ctx, "1"); got != nil {
+		t.Errorf("expected task 1 rolled back, got %+v", got)
+	}
+	if tags, _ := repo.TagCounts(ctx); len(tags) != 0 {
+		t.Errorf("expected tags rolled back, got %v", tags)
+	}
+
+	// Sem erro: grava tudo
+	err = repo.WithinTx(ctx, func(tx TaskRepositoryInterface) error {
+		if err := tx.Save(ctx, newTask("1")); err != nil {
+			return err
+		}
+		task, err := tx.FindByID(ctx, "1")
+		if err != nil || task == nil {
+			return fmt.Errorf("expected task visible inside tx, got %v", err)
+		}
+		task.Title = "Updated"
+		return tx.Update(ctx, task)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := repo.FindByID(ctx, "1"); got == nil || got.Title != "Updated" || got.Version != 2 {
+		t.Errorf("expected committed task, got %+v", got)
+	}
+}
+
+func TestSQLiteRepository_KeysetPagination(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// b e c com o mesmo created_at: o id desempata
+	for i, id := range []string{"a", "b", "c", "d"} {
+		createdAt := base.Add(time.Duration(i) * time.Minute)
+		if id == "c" {
+			createdAt = base.Add(time.Minute)
+		}
+		task := &model.Task{ID: id, Title: id, Status: model.StatusPending, Priority: model.PriorityMedium, CreatedAt: createdAt, UpdatedAt: createdAt}
+		if err := repo.Save(ctx, task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	first, err := repo.FindAll(ctx, model.TaskFilter{}, ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 2 || first[0].ID != "a" || first[1].ID != "b" {
+		t.Fatalf("unexpected first page: %v", first)
+	}
+
+	after := NewCursor(first[1], nil)
+	second, err := repo.FindAll(ctx, model.TaskFilter{}, ListOptions{Limit: 2, After: &after})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second) != 2 || second[0].ID != "c" || second[1].ID != "d" {
+		t.Fatalf("unexpected second page: %v", second)
+	}
+}
+
+func TestSQLiteRepository_Filter(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tasks := []model.Task{
+		{ID: "1", Title: "Fix LOGIN bug", Description: "100% broken", Status: model.StatusPending, Priority: model.PriorityHigh},
+		{ID: "2", Title: "Write docs", Description: "user_guide", Status: model.StatusCompleted, Priority: model.PriorityMedium},
+		{ID: "3", Title: "Login page", Description: "", Status: model.StatusPending, Priority: model.PriorityLow},
+	}
+	tasks[0].DueAt = timePtr(base.Add(3 * time.Hour))
+	tasks[1].DueAt = timePtr(base.Add(time.Hour))
+	now := base.Add(5 * time.Hour)
+	overdue, notOverdue := true, false
+
+	for i := range tasks {
+		tasks[i].CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		tasks[i].UpdatedAt = tasks[i].CreatedAt
+		if err := repo.Save(ctx, &tasks[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter model.TaskFilter
+		want   []string
+	}{
+		{name: "multi-value priority", filter: model.TaskFilter{Priorities: []string{model.PriorityHigh, model.PriorityMedium}}, want: []string{"1", "2"}},
+		{name: "status and priority", filter: model.TaskFilter{Statuses: []string{model.StatusPending}, Priorities: []string{model.PriorityLow}}, want: []string{"3"}},
+		{name: "title ignores case", filter: model.TaskFilter{TitleContains: "login"}, want: []string{"1", "3"}},
+		{name: "percent is literal", filter: model.TaskFilter{DescriptionContains: "0%"}, want: []string{"1"}},
+		{name: "underscore is literal", filter: model.TaskFilter{DescriptionContains: "r_g"}, want: []string{"2"}},
+		{name: "created range", filter: model.TaskFilter{CreatedAfter: timePtr(base.Add(time.Hour)), CreatedBefore: timePtr(base.Add(2 * time.Hour))}, want: []string{"2"}},
+		{name: "updated after", filter: model.TaskFilter{UpdatedAfter: timePtr(base.Add(2 * time.Hour))}, want: []string{"3"}},
+		{name: "due before", filter: model.TaskFilter{DueBefore: timePtr(base.Add(2 * time.Hour))}, want: []string{"2"}},
+		{name: "overdue ignores completed", filter: model.TaskFilter{Overdue: &overdue, Now: now}, want: []string{"1"}},
+		{name: "not overdue", filter: model.TaskFilter{Overdue: &notOverdue, Now: now}, want: []string{"2", "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.FindAll(ctx, tt.filter, ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i, id := range tt.want {
+				if got[i].ID != id {
+					t.Errorf("position %d: expected %q, got %q", i, id, got[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteRepository_SortAndKeyset(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tasks := []model.Task{
+		{ID: "1", Title: "banana", Priority: model.PriorityLow},
+		{ID: "2", Title: "Apple", Priority: model.PriorityHigh},
+		{ID: "3", Title: "cherry", Priority: model.PriorityMedium},
+		{ID: "4", Title: "date", Priority: model.PriorityHigh},
+		{ID: "5", Title: "elder", Priority: model.PriorityMedium},
+	}
+	// Prazos com empate (3 e 4) e tasks sem prazo (2 e 5), que ficam sempre no fim
+	tasks[0].DueAt = timePtr(base.Add(2 * time.Hour))
+	tasks[2].DueAt = timePtr(base.Add(time.Hour))
+	tasks[3].DueAt = timePtr(base.Add(time.Hour))
+
+	for i := range tasks {
+		tasks[i].Status = model.StatusPending
+		tasks[i].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		tasks[i].UpdatedAt = tasks[i].CreatedAt
+		if err := repo.Save(ctx, &tasks[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		sort []SortField
+		want []string
+	}{
+		{name: "priority desc uses semantic order", sort: []SortField{{Name: "priority", Desc: true}}, want: []string{"2", "4", "3", "5", "1"}},
+		{name: "priority asc then newest", sort: []SortField{{Name: "priority"}, {Name: "created_at", Desc: true}}, want: []string{"1", "5", "3", "4", "2"}},
+		{name: "title ignores case", sort: []SortField{{Name: "title"}}, want: []string{"2", "1", "3", "4", "5"}},
+		{name: "due_at asc nulls last", sort: []SortField{{Name: "due_at"}}, want: []string{"3", "4", "1", "2", "5"}},
+		{name: "due_at desc nulls last", sort: []SortField{{Name: "due_at", Desc: true}}, want: []string{"1", "3", "4", "2", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Páginas de 2 itens: o keyset precisa reproduzir a mesma ordem
+			var (
+				got   []string
+				after *Cursor
+			)
+			for {
+				page, err := repo.FindAll(ctx, model.TaskFilter{}, ListOptions{Sort: tt.sort, Limit: 2, After: after})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for _, task := range page {
+					got = append(got, task.ID)
+				}
+				if len(page) < 2 {
+					break
+				}
+				c := NewCursor(page[len(page)-1], tt.sort)
+				after = &c
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	// Campo fora da whitelist nunca chega ao SQL
+	if _, err := repo.FindAll(ctx, model.TaskFilter{}, ListOptions{Sort: []SortField{{Name: "id; DROP TABLE tasks"}}}); err == nil {
+		t.Error("expected error for unknown sort field")
+	}
+}
+
+func TestSQLiteRepository_SearchFallback(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tasks := []model.Task{
+		{ID: "1", Title: "Fix login bug", Description: "users can't login", Status: model.StatusPending},
+		{ID: "2", Title: "Write docs", Description: "explain the login flow", Status: model.StatusCompleted},
+		{ID: "3", Title: "Refactor", Description: "nothing related", Status: model.StatusPending},
+	}
+	for i := range tasks {
+		tasks[i].Priority = model.PriorityMedium
+		tasks[i].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		tasks[i].UpdatedAt = tasks[i].CreatedAt
+		if err := repo.Save(ctx, &tasks[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	results, err := repo.Search(ctx, "LOGIN bug", model.TaskFilter{}, SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "1" || results[1].ID != "2" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	// login no título (2) + bug no título (2) + login na descrição (1)
+	if results[0].Score != 5 || results[1].Score != 1 {
+		t.Errorf("unexpected scores: %v, %v", results[0].Score, results[1].Score)
+	}
+
+	// Filtros da listagem também valem na busca
+	results, err = repo.Search(ctx, "login", model.TaskFilter{Statuses: []string{model.StatusCompleted}}, SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("unexpected filtered results: %+v", results)
+	}
+
+	// Offset
+	results, err = repo.Search(ctx, "login", model.TaskFilter{}, SearchOptions{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "2" {
+		t.Errorf("unexpected offset results: %+v", results)
+	}
+}
+
+func TestSQLiteRepository_Tags(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tasks := []model.Task{
+		{ID: "1", Title: "API", Tags: []string{"backend", "bug"}},
+		{ID: "2", Title: "Docs", Tags: []string{"docs"}},
+		{ID: "3", Title: "Login", Tags: []string{"backend"}},
+		{ID: "4", Title: "Untagged"},
+	}
+	for i := range tasks {
+		tasks[i].Status = model.StatusPending
+		tasks[i].Priority = model.PriorityMedium
+		tasks[i].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		tasks[i].UpdatedAt = tasks[i].CreatedAt
+		if err := repo.Save(ctx, &tasks[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	got, err := repo.FindByID(ctx, "1")
+	if err != nil || got == nil || len(got.Tags) != 2 || got.Tags[0] != "backend" {
+		t.Fatalf("expected tags [backend bug], got %+v (%v)", got, err)
+	}
+	if untagged, _ := repo.FindByID(ctx, "4"); untagged.Tags == nil {
+		t.Error("expected empty tag list, got nil")
+	}
+
+	tests := []struct {
+		name   string
+		filter model.TaskFilter
+		want   []string
+	}{
+		{name: "any", filter: model.TaskFilter{Tags: []string{"bug", "docs"}}, want: []string{"1", "2"}},
+		{name: "all", filter: model.TaskFilter{Tags: []string{"backend", "bug"}, TagMode: model.TagMatchAll}, want: []string{"1"}},
+		{name: "all with one tag", filter: model.TaskFilter{Tags: []string{"backend"}, TagMode: model.TagMatchAll}, want: []string{"1", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := repo.FindAll(ctx, tt.filter, ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var ids []string
+			for _, task := range list {
+				ids = append(ids, task.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, ids)
+			}
+		})
+	}
+
+	// Update substitui as tags; tasks na lixeira não entram na contagem
+	got.Tags = []string{"docs"}
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Delete(ctx, "3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts, err := repo.TagCounts(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []model.TagCount{{Tag: "docs", Count: 2}}
+	if len(counts) != len(want) || counts[0] != want[0] {
+		t.Errorf("expected %+v, got %+v", want, counts)
+	}
+
+	// Purge apaga as tags junto (ON DELETE CASCADE)
+	if err := repo.Purge(ctx, "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var left int
+	if err := repo.db.QueryRow("SELECT COUNT(*) FROM task_tags WHERE task_id = '2'").Scan(&left); err != nil || left != 0 {
+		t.Errorf("expected tags purged with the task, got %d (%v)", left, err)
+	}
+}
+
+func TestSQLiteRepository_Subtasks(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	parentID := "p"
+
+	tasks := []model.Task{
+		{ID: "p", Title: "Parent", Status: model.StatusPending},
+		{ID: "c1", Title: "Child 1", Status: model.StatusCompleted, ParentID: &parentID},
+		{ID: "c2", Title: "Child 2", Status: model.StatusPending, ParentID: &parentID},
+		{ID: "c3", Title: "Child 3", Status: model.StatusPending, ParentID: &parentID},
+	}
+	for i := range tasks {
+		tasks[i].Priority = model.PriorityMedium
+		tasks[i].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		tasks[i].UpdatedAt = tasks[i].CreatedAt
+		if err := repo.Save(ctx, &tasks[i]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Subtask na lixeira não conta
+	if err := repo.Delete(ctx, "c3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	children, err := repo.FindAll(ctx, model.TaskFilter{ParentID: "p"}, ListOptions{})
+	if err != nil || len(children) != 2 || *children[0].ParentID != "p" {
+		t.Fatalf("expected 2 children of p, got %+v (%v)", children, err)
+	}
+
+	stats, err := repo.ChildStats(ctx, []string{"p", "c1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats["p"] != (model.ChildStats{Total: 2, Closed: 1}) {
+		t.Errorf("expected 2 children with 1 closed, got %+v", stats["p"])
+	}
+	if _, ok := stats["c1"]; ok {
+		t.Error("expected no stats for a task without children")
+	}
+
+	// Purge do pai transforma as subtasks em tasks raiz
+	if err := repo.Purge(ctx, "p"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	child, err := repo.FindByID(ctx, "c2")
+	if err != nil || child.ParentID != nil {
+		t.Errorf("expected c2 detached from purged parent, got %+v (%v)", child, err)
+	}
+}
+
+func TestSQLiteRepository_Dependencies(t *testing.T) {
+	repo := newSQLiteTestRepo(t)
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, id := range []string{"a", "b", "c"} {
+		task := model.Task{ID: id, Title: id, Status: model.StatusPending, Priority: model.PriorityMedium}
+		task.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		task.UpdatedAt = task.CreatedAt
+		if err := repo.Save(ctx, &task); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// A linha do lock vem da migration
+	if err := repo.LockDependencies(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a bloqueada por b e c
+	for _, blocker := range []string{"b", "c"} {
+		if err := repo.AddDependency(ctx, "a", blocker); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	blockers, err := repo.FindBlockers(ctx, "a")
+	if err != nil || len(blockers) != 2 || blockers[0].ID != "b" {
+		t.Fatalf("expected blockers [b c], got %+v (%v)", blockers, err)
+	}
+	blocking, err := repo.FindBlocking(ctx, "c")
+	if err != nil || len(blocking) != 1 || blocking[0].ID != "a" {
+		t.Fatalf("expected c blocking [a], got %+v (%v)", blocking, err)
+	}
+
+	// Bloqueio na lixeira some das listas, mas a aresta continua no grafo
+	if err := repo.Delete(ctx, "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if blockers, _ := repo.FindBlockers(ctx, "a"); len(blockers) != 1 {
+		t.Errorf("expected only c as active blocker, got %+v", blockers)
+	}
+	if deps, _ := repo.FindDependencies(ctx); len(deps) != 2 {
+		t.Errorf("expected 2 edges, got %+v", deps)
+	}
+
+	// Purge apaga as arestas (ON DELETE CASCADE)
+	if err := repo.Purge(ctx, "c"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.RemoveDependency(ctx, "a", "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deps, _ := repo.FindDependencies(ctx); len(deps) != 0 {
+		t.Errorf("expected no edges left, got %+v", deps)
+	}
+}
+
+func TestSQLiteRepository_Idempotency(t *testing.T) {
+	store := newSQLiteTestRepo(t).Idempotency()
+	ctx := context.Background()
+	now := time.Now()
+
+	// A reserva vence em um minuto; a resposta gravada, em uma hora
+	record := model.IdempotencyRecord{Key: "k1", Fingerprint: "abc", Owner: "o1", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+	if existing, err := store.Reserve(ctx, record); err != nil || existing != nil {
+		t.Fatalf("expected key reserved, got %v (%v)", existing, err)
+	}
+
+	// Segunda reserva devolve a chave em andamento
+	existing, err := store.Reserve(ctx, record)
+	if err != nil || existing == nil || !existing.Pending() || existing.Fingerprint != "abc" {
+		t.Fatalf("expected pending record, got %+v (%v)", existing, err)
+	}
+
+	record.StatusCode = 201
+	record.ContentType = "application/json"
+	record.ETag = `"1"`
+	record.Body = []byte(`{"id":"1"}`)
+	record.ExpiresAt = now.Add(time.Hour)
+	if err := store.Complete(ctx, record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	retry := record
+	retry.CreatedAt = now.Add(2 * time.Minute)
+	existing, err = store.Reserve(ctx, retry)
+	if err != nil || existing == nil || existing.StatusCode != 201 || string(existing.Body) != `{"id":"1"}` || existing.ETag != `"1"` {
+		t.Fatalf("expected stored response, got %+v (%v)", existing, err)
+	}
+
+	// Depois do TTL a chave pode ser reutilizada
+	later := record
+	later.Owner = "o2"
+	later.CreatedAt = now.Add(2 * time.Hour)
+	later.ExpiresAt = later.CreatedAt.Add(time.Hour)
+	if existing, err := store.Reserve(ctx, later); err != nil || existing != nil {
+		t.Errorf("expected expired key reserved again, got %+v (%v)", existing, err)
+	}
+
+	// A reserva antiga não mexe mais na chave
+	if err := store.Complete(ctx, record); !errors.Is(err, ErrReservationLost) {
+		t.Errorf("expected ErrReservationLost completing with old owner, got %v", err)
+	}
+	if err := store.Release(ctx, "k1", "o1"); !errors.Is(err, ErrReservationLost) {
+		t.Errorf("expected ErrReservationLost releasing with old owner, got %v", err)
+	}
+	if existing, _ := store.Reserve(ctx, later); existing == nil || !existing.Pending() || existing.Owner != "o2" {
+		t.Errorf("expected new reservation untouched, got %+v", existing)
+	}
+
+	if err := store.Release(ctx, "k1", "o2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if existing, err := store.Reserve(ctx, record); err != nil || existing != nil {
+		t.Errorf("expected released key reserved again, got %+v (%v)", existing, err)
+	}
+}
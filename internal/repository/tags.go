@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Tags ficam na tabela task_tags (task_id, tag). As tasks são lidas primeiro e as
+// tags vêm numa segunda query para a página inteira, evitando um JOIN que repetiria as linhas.
+
+// replaceTags troca as tags da task dentro da transação do Save/Update
+func (r *TaskRepository) replaceTags(ctx context.Context, tx querier, taskID string, tags []string) error {
+	if _, err := tx.ExecContext(ctx, r.dialect.rebind("DELETE FROM task_tags WHERE task_id = ?"), taskID); err != nil {
+		return fmt.Errorf("erro ao limpar tags da task:%w", err)
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	values := strings.TrimSuffix(strings.Repeat("(?, ?), ", len(tags)), ", ")
+	args := make([]any, 0, len(tags)*2)
+	for _, tag := range tags {
+		args = append(args, taskID, tag)
+	}
+
+	query := "INSERT INTO task_tags (task_id, tag) VALUES " + values
+	if _, err := tx.ExecContext(ctx, r.dialect.rebind(query), args...); err != nil {
+		return fmt.Errorf("erro ao salvar tags da task:%w", err)
+	}
+	return nil
+}
+
+// loadTags preenche Tags de cada task com uma única query; task sem tag fica com lista vazia
+func (r *TaskRepository) loadTags(ctx context.Context, tasks []*model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*model.Task, len(tasks))
+	args := make([]any, 0, len(tasks))
+	for _, task := range tasks {
+		task.Tags = []string{}
+		byID[task.ID] = task
+		args = append(args, task.ID)
+	}
+
+	query := "SELECT task_id, tag FROM task_tags WHERE task_id IN (" + placeholders(len(args)) + ") ORDER BY tag"
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar tags:%w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, tag string
+		if err := rows.Scan(&taskID, &tag); err != nil {
+			return fmt.Errorf("erro ao ler tags:%w", err)
+		}
+		if task, ok := byID[taskID]; ok {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao percorrer tags:%w", err)
+	}
+
+	return nil
+}
+
+// TagCounts conta em quantas tasks ativas cada tag aparece, das mais usadas para as menos
+func (r *TaskRepository) TagCounts(ctx context.Context) ([]model.TagCount, error) {
+	query := `
+		SELECT tt.tag, COUNT(*) AS total
+		FROM task_tags tt
+		JOIN tasks t ON t.id = tt.task_id
+		WHERE t.deleted_at IS NULL
+		GROUP BY tt.tag
+		ORDER BY total DESC, tt.tag ASC
+	`
+
+	rows, err := r.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao contar tags:%w", err)
+	}
+	defer rows.Close()
+
+	var counts []model.TagCount
+	for rows.Next() {
+		var c model.TagCount
+		if err := rows.Scan(&c.Tag, &c.Count); err != nil {
+			return nil, fmt.Errorf("erro ao ler contagem de tags:%w", err)
+		}
+		counts = append(counts, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao percorrer contagem de tags:%w", err)
+	}
+
+	return counts, nil
+}
+
+// tagClause filtra por tag com subquery em task_tags.
+// "all" exige que a task tenha todas as tags: as tags do filtro chegam sem repetição, então basta contar.
+func tagClause(tags []string, mode string) (string, []any) {
+	if len(tags) == 0 {
+		return "", nil
+	}
+
+	args := make([]any, 0, len(tags))
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+
+	sub := "SELECT task_id FROM task_tags WHERE tag IN (" + placeholders(len(tags)) + ")"
+	if mode == model.TagMatchAll {
+		sub += fmt.Sprintf(" GROUP BY task_id HAVING COUNT(*) = %d", len(tags))
+	}
+	return " AND id IN (" + sub + ")", args
+}
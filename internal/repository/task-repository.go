@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/DinizJ/desafio/internal/model"
 )
@@ -14,63 +17,210 @@ import (
 // DB
 
 type TaskRepository struct {
-	db *sql.DB // connect
+	db      *sql.DB // connect
+	tx      *sql.Tx // não nil dentro de WithinTx: todas as queries usam a transação
+	dialect dialect // MySQL, SQLite ou PostgreSQL
 }
 
 // Adjust Layers
+// NewTaskRepository cria o repository para MySQL
 func NewTaskRepository(db *sql.DB) *TaskRepository {
 	return &TaskRepository{
-		db: db,
+		db:      db,
+		dialect: dialectMySQL,
 	}
 }
 
+// exec, query e queryRow adaptam a query ao dialect antes de executar
+
+func (r *TaskRepository) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.conn().ExecContext(ctx, r.dialect.rebind(query), args...)
+}
+
+func (r *TaskRepository) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return r.conn().QueryContext(ctx, r.dialect.rebind(query), args...)
+}
+
+func (r *TaskRepository) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return r.conn().QueryRowContext(ctx, r.dialect.rebind(query), args...)
+}
+
+// Colunas lidas por todas as queries de SELECT, na ordem esperada por scanTask
+const taskColumns = `id, parent_id, title, description, status, priority, due_at, completed_at, reopened_at, reopened_by, version, created_at, updated_at, deleted_at`
+
+// rowScanner é satisfeito tanto por *sql.Row quanto por *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// Datas sempre gravadas em UTC: o MySQL já converte, mas no SQLite elas
+// viram texto e só ordenam corretamente se estiverem todas no mesmo fuso
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// scanTask lê as colunas de taskColumns; extra recebe colunas adicionais do SELECT (ex.: score)
+func scanTask(row rowScanner, extra ...any) (model.Task, error) {
+	var task model.Task
+	dest := []any{
+		&task.ID,
+		&task.ParentID, // NULL vira nil
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Priority,
+		&task.DueAt,       // NULL vira nil
+		&task.CompletedAt, // NULL vira nil
+		&task.ReopenedAt,  // NULL vira nil
+		&task.ReopenedBy,  // NULL vira nil
+		&task.Version,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.DeletedAt, // NULL vira nil
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return task, err
+}
+
 // Save put new task
 
+// Save grava a task e as tags na mesma transação
 func (r *TaskRepository) Save(ctx context.Context, task *model.Task) error {
+	_, err := r.insert(ctx, task, false)
+	return err
+}
+
+//SaveIfAbsent
+
+// SaveIfAbsent grava a task só se o id ainda não existe (nem na lixeira). A checagem
+// e o INSERT são um só comando, então duas requisições com o mesmo id não duplicam a task.
+func (r *TaskRepository) SaveIfAbsent(ctx context.Context, task *model.Task) (bool, error) {
+	if !r.dialect.validID(task.ID) {
+		return false, fmt.Errorf("id inválido: %q", task.ID)
+	}
+	return r.insert(ctx, task, true)
+}
+
+// insert grava a task e as tags numa transação; ifAbsent ignora id já existente e devolve false
+func (r *TaskRepository) insert(ctx context.Context, task *model.Task, ifAbsent bool) (bool, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("erro ao iniciar transação:%w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO tasks (id, title, description, status, priority, created_at, updated_at, deleted_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)	    
-    `
+		INSERT INTO tasks (id, parent_id, title, description, status, priority, due_at, completed_at, reopened_at, reopened_by, version, created_at, updated_at, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if ifAbsent {
+		query += r.dialect.onConflictDoNothing("id")
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, r.dialect.rebind(query),
 		task.ID,
+		task.ParentID,
 		task.Title,
 		task.Description,
 		task.Status,
 		task.Priority,
-		task.CreatedAt,
-		task.UpdatedAt,
-		task.DeletedAt,
+		utcPtr(task.DueAt),
+		utcPtr(task.CompletedAt),
+		utcPtr(task.ReopenedAt),
+		task.ReopenedBy,
+		task.Version,
+		utc(task.CreatedAt),
+		utc(task.UpdatedAt),
+		utcPtr(task.DeletedAt),
 	)
 
 	if err != nil {
-		return fmt.Errorf("erro ao salvar task no banco:%w", err)
+		return false, fmt.Errorf("erro ao salvar task no banco:%w", err)
+	}
+	if ifAbsent {
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return false, fmt.Errorf("erro ao salvar task no banco:%w", err)
+		}
+		if affected == 0 {
+			return false, nil
+		}
 	}
 
-	return nil
+	if err := r.replaceTags(ctx, tx, task.ID, task.Tags); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("erro ao salvar task no banco:%w", err)
+	}
+	return true, nil
 }
 
 //FindByID
 
+// FindByID ignora tasks que estão na lixeira
 func (r *TaskRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
 	query := `
-	SELECT id, title, description, status, priority, created_at, updated_at, deleted_at
+	SELECT ` + taskColumns + `
  	FROM tasks
- 	WHERE id = ?`
+ 	WHERE id = ? AND deleted_at IS NULL`
 
-	row := r.db.QueryRowContext(ctx, query, id)
+	return r.findOne(ctx, query, id)
+}
 
-	var task model.Task
-	err := row.Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.Priority,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-		&task.DeletedAt,
-	)
+//FindByIDForUpdate
+
+// FindByIDForUpdate é o FindByID com a linha travada (SELECT ... FOR UPDATE) até o fim
+// da transação do WithinTx; fora dela o lock acaba junto com a query
+func (r *TaskRepository) FindByIDForUpdate(ctx context.Context, id string) (*model.Task, error) {
+	query := `
+	SELECT ` + taskColumns + `
+ 	FROM tasks
+ 	WHERE id = ? AND deleted_at IS NULL` + r.dialect.forUpdate()
+
+	return r.findOne(ctx, query, id)
+}
+
+//FindDeletedByID
+
+// FindDeletedByID busca uma task apenas se ela estiver na lixeira
+func (r *TaskRepository) FindDeletedByID(ctx context.Context, id string) (*model.Task, error) {
+	query := `
+	SELECT ` + taskColumns + `
+ 	FROM tasks
+ 	WHERE id = ? AND deleted_at IS NOT NULL`
+
+	return r.findOne(ctx, query, id)
+}
+
+//FindDeletedByIDForUpdate
+
+// FindDeletedByIDForUpdate é o FindDeletedByID com a linha travada, como no FindByIDForUpdate
+func (r *TaskRepository) FindDeletedByIDForUpdate(ctx context.Context, id string) (*model.Task, error) {
+	query := `
+	SELECT ` + taskColumns + `
+ 	FROM tasks
+ 	WHERE id = ? AND deleted_at IS NOT NULL` + r.dialect.forUpdate()
+
+	return r.findOne(ctx, query, id)
+}
+
+func (r *TaskRepository) findOne(ctx context.Context, query string, id string) (*model.Task, error) {
+	if !r.dialect.validID(id) {
+		return nil, nil
+	}
+
+	row := r.queryRow(ctx, query, id)
+
+	task, err := scanTask(row)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
@@ -80,47 +230,182 @@ func (r *TaskRepository) FindByID(ctx context.Context, id string) (*model.Task,
 		return nil, fmt.Errorf("erro ao buscar id no banco:%w", err)
 	}
 
+	if err := r.loadTags(ctx, []*model.Task{&task}); err != nil {
+		return nil, err
+	}
+
 	return &task, nil
 }
 
+//Stream
+
+// Stream percorre as tasks do filtro em páginas do FindAll (ver streamTasks)
+func (r *TaskRepository) Stream(ctx context.Context, filter model.TaskFilter, fn func(model.Task) error) error {
+	return streamTasks(ctx, r.FindAll, filter, fn)
+}
+
 //FindAll
 
-func (r *TaskRepository) FindAll(ctx context.Context, status string) ([]model.Task, error) {
+// FindAll lista as tasks ativas na ordem de opts.Sort (sempre desempatando pelo id),
+// paginando por keyset a partir de opts.After
+func (r *TaskRepository) FindAll(ctx context.Context, filter model.TaskFilter, opts ListOptions) ([]model.Task, error) {
+	// parent_id é UUID no PostgreSQL: id malformado não tem filhos
+	if filter.ParentID != "" && !r.dialect.validID(filter.ParentID) {
+		return nil, nil
+	}
+
+	where, args := filterClause(filter)
+
 	query := `
- 		SELECT id, title, description, status, priority, created_at, updated_at, deleted_at
+ 		SELECT ` + taskColumns + `
 		FROM tasks
-	`
+		WHERE deleted_at IS NULL
+	` + where
+
+	sort := opts.sortFields()
+
+	if opts.After != nil {
+		keyset, keysetArgs, err := keysetClause(sort, *opts.After)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao paginar tasks:%w", err)
+		}
+		query += keyset
+		args = append(args, keysetArgs...)
+	}
 
+	orderBy, err := orderByClause(sort)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ordenar tasks:%w", err)
+	}
+	query += orderBy
+
+	if opts.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(opts.Limit)
+	}
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao executar query de tasks:%w", err)
+	}
+	defer rows.Close()
+
+	tasks, err := collectTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, r.loadTags(ctx, model.TaskPtrs(tasks))
+}
+
+// filterClause monta os "AND ..." do filtro, com os argumentos na mesma ordem dos "?"
+func filterClause(f model.TaskFilter) (string, []any) {
 	var (
-		rows *sql.Rows
-		err  error
+		b    strings.Builder
+		args []any
 	)
 
-	if status != "" {
-		query += " WHERE status = ? "
-		rows, err = r.db.QueryContext(ctx, query, status)
-	} else {
-		rows, err = r.db.QueryContext(ctx, query)
+	in := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		b.WriteString(" AND " + column + " IN (" + placeholders(len(values)) + ")")
+		for _, v := range values {
+			args = append(args, v)
+		}
+	}
+
+	compare := func(column, op string, t *time.Time) {
+		if t == nil {
+			return
+		}
+		b.WriteString(" AND " + column + " " + op + " ?")
+		args = append(args, utc(*t))
+	}
+
+	// LOWER dos dois lados: o LIKE do PostgreSQL diferencia maiúsculas, o do MySQL e SQLite não
+	contains := func(column, text string) {
+		if text == "" {
+			return
+		}
+		b.WriteString(" AND LOWER(" + column + ") LIKE ? ESCAPE '!'")
+		args = append(args, "%"+escapeLike(strings.ToLower(text))+"%")
+	}
+
+	if f.ParentID != "" {
+		b.WriteString(" AND parent_id = ?")
+		args = append(args, f.ParentID)
+	}
+
+	in("status", f.Statuses)
+	in("priority", f.Priorities)
+	compare("created_at", ">=", f.CreatedAfter)
+	compare("created_at", "<", f.CreatedBefore)
+	compare("updated_at", ">=", f.UpdatedAfter)
+	compare("updated_at", "<", f.UpdatedBefore)
+	compare("due_at", ">=", f.DueAfter)
+	compare("due_at", "<", f.DueBefore)
+	contains("title", f.TitleContains)
+	contains("description", f.DescriptionContains)
+
+	tags, tagArgs := tagClause(f.Tags, f.TagMode)
+	b.WriteString(tags)
+	args = append(args, tagArgs...)
+
+	// Atrasada: tem prazo, o prazo já passou e a task não foi encerrada
+	if f.Overdue != nil {
+		closed := placeholders(len(model.ClosedStatuses))
+		if *f.Overdue {
+			b.WriteString(" AND due_at IS NOT NULL AND due_at < ? AND status NOT IN (" + closed + ")")
+		} else {
+			b.WriteString(" AND (due_at IS NULL OR due_at >= ? OR status IN (" + closed + "))")
+		}
+		args = append(args, utc(f.Reference()))
+		for _, s := range model.ClosedStatuses {
+			args = append(args, s)
+		}
 	}
 
+	return b.String(), args
+}
+
+// placeholders gera "?, ?, ?" com n posições
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// escapeLike neutraliza os curingas do LIKE. O escape é "!" porque a barra invertida tem
+// significado especial nas strings do MySQL e não se comporta igual nos três bancos.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+//FindDeleted
+
+// FindDeleted lista a lixeira, das deleções mais recentes para as mais antigas
+func (r *TaskRepository) FindDeleted(ctx context.Context) ([]model.Task, error) {
+	query := `
+ 		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`
+
+	rows, err := r.query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao executar query de tasks:%w", err)
+		return nil, fmt.Errorf("erro ao executar query da lixeira:%w", err)
 	}
 	defer rows.Close()
 
+	tasks, err := collectTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return tasks, r.loadTags(ctx, model.TaskPtrs(tasks))
+}
+
+func collectTasks(rows *sql.Rows) ([]model.Task, error) {
 	var tasks []model.Task
 	for rows.Next() {
-		var task model.Task
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.Priority,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			&task.DeletedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao ler dados de tasks:%w", err)
 		}
@@ -136,35 +421,189 @@ func (r *TaskRepository) FindAll(ctx context.Context, status string) ([]model.Ta
 
 //Update
 
+// Update grava os campos e substitui as tags da task, na mesma transação
 func (r *TaskRepository) Update(ctx context.Context, task *model.Task) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação:%w", err)
+	}
+	defer tx.Rollback()
+
+	// Só grava se a versão ainda é a que foi lida: a comparação e o incremento
+	// acontecem no mesmo UPDATE, então duas escritas concorrentes não se sobrescrevem
 	query := `
 	UPDATE tasks
-	SET title = ?, description = ?, status = ?, priority = ?, updated_at = ?
-	WHERE id = ?
+	SET parent_id = ?, title = ?, description = ?, status = ?, priority = ?, due_at = ?,
+		completed_at = ?, reopened_at = ?, reopened_by = ?, updated_at = ?, version = version + 1
+	WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, r.dialect.rebind(query),
+		task.ParentID,
 		task.Title,
 		task.Description,
 		task.Status,
 		task.Priority,
-		task.UpdatedAt,
+		utcPtr(task.DueAt),
+		utcPtr(task.CompletedAt),
+		utcPtr(task.ReopenedAt),
+		task.ReopenedBy,
+		utc(task.UpdatedAt),
 		task.ID,
+		task.Version,
 	)
 	if err != nil {
 		return fmt.Errorf("erro ao atualizar tasks:%w", err)
 	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar tasks:%w", err)
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	if err := r.replaceTags(ctx, tx, task.ID, task.Tags); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao atualizar tasks:%w", err)
+	}
+	task.Version++
 	return nil
 }
 
 //Delete
 
+// Delete faz soft delete: a task vai para a lixeira e pode ser restaurada. A versão é
+// incrementada, como em toda alteração; se a task não está ativa, devolve ErrVersionConflict.
 func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+	if !r.dialect.validID(id) {
+		return ErrVersionConflict
+	}
+
 	query := `
-		DELETE FROM tasks WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
+		UPDATE tasks SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL`
+	result, err := r.exec(ctx, query, utc(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("erro ao deletar task:%w", err)
+	}
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("erro ao deletar task:%w", err)
 	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
 	return nil
 }
+
+//Restore
+
+// Restore tira a task da lixeira, incrementando a versão como o Delete. Se a task
+// não está na lixeira, devolve ErrVersionConflict.
+func (r *TaskRepository) Restore(ctx context.Context, id string) error {
+	if !r.dialect.validID(id) {
+		return ErrVersionConflict
+	}
+
+	query := `
+		UPDATE tasks SET deleted_at = NULL, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL`
+	result, err := r.exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("erro ao restaurar task:%w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao restaurar task:%w", err)
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+//Purge
+
+// Purge remove a task definitivamente, esteja ela na lixeira ou não.
+// As subtasks viram tasks raiz (o SQLite não tem a FK com ON DELETE SET NULL).
+func (r *TaskRepository) Purge(ctx context.Context, id string) error {
+	if !r.dialect.validID(id) {
+		return nil
+	}
+
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação:%w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, r.dialect.rebind("UPDATE tasks SET parent_id = NULL WHERE parent_id = ?"), id); err != nil {
+		return fmt.Errorf("erro ao soltar subtasks:%w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, r.dialect.rebind("DELETE FROM tasks WHERE id = ?"), id); err != nil {
+		return fmt.Errorf("erro ao remover task definitivamente:%w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao remover task definitivamente:%w", err)
+	}
+	return nil
+}
+
+//ChildStats
+
+// ChildStats conta as subtasks ativas (total e encerradas) de cada task informada.
+// Tasks sem subtasks ficam fora do mapa.
+func (r *TaskRepository) ChildStats(ctx context.Context, parentIDs []string) (map[string]model.ChildStats, error) {
+	stats := map[string]model.ChildStats{}
+
+	var idArgs []any
+	for _, id := range parentIDs {
+		if r.dialect.validID(id) {
+			idArgs = append(idArgs, id)
+		}
+	}
+	if len(idArgs) == 0 {
+		return stats, nil
+	}
+
+	var closedArgs []any
+	for _, s := range model.ClosedStatuses {
+		closedArgs = append(closedArgs, s)
+	}
+
+	query := `
+		SELECT parent_id, COUNT(*),
+			SUM(CASE WHEN status IN (` + placeholders(len(closedArgs)) + `) THEN 1 ELSE 0 END)
+		FROM tasks
+		WHERE deleted_at IS NULL AND parent_id IN (` + placeholders(len(idArgs)) + `)
+		GROUP BY parent_id
+	`
+	// Mesma ordem dos "?" na query: CASE, depois IN
+	args := append(closedArgs, idArgs...)
+
+	rows, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao contar subtasks:%w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			parentID string
+			c        model.ChildStats
+		)
+		if err := rows.Scan(&parentID, &c.Total, &c.Closed); err != nil {
+			return nil, fmt.Errorf("erro ao ler contagem de subtasks:%w", err)
+		}
+		stats[parentID] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao percorrer contagem de subtasks:%w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Transações do TaskRepository. Fora de WithinTx cada método usa o pool (r.db) e as
+// escritas com vários comandos (Save, Update, Purge) abrem a própria transação.
+// Dentro de WithinTx o repository recebido está preso a uma transação (r.tx), e
+// todos os métodos rodam nela: o commit ou rollback é um só, no fim do WithinTx.
+
+// querier é o que *sql.DB e *sql.Tx têm em comum
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn devolve a transação do WithinTx, se houver, ou o pool
+func (r *TaskRepository) conn() querier {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// localTx é a transação de uma escrita com vários comandos. Dentro de WithinTx ela
+// é a transação externa, e Commit e Rollback não fazem nada: quem decide é o WithinTx.
+type localTx struct {
+	*sql.Tx
+	owned bool
+}
+
+func (t localTx) Commit() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t localTx) Rollback() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+// begin abre a transação de uma escrita, ou reaproveita a do WithinTx
+func (r *TaskRepository) begin(ctx context.Context) (localTx, error) {
+	if r.tx != nil {
+		return localTx{Tx: r.tx}, nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return localTx{}, err
+	}
+	return localTx{Tx: tx, owned: true}, nil
+}
+
+//WithinTx
+
+// WithinTx roda fn numa transação: se fn devolver erro, nada do que ela gravou fica.
+// Chamado de dentro de outro WithinTx, usa a mesma transação.
+func (r *TaskRepository) WithinTx(ctx context.Context, fn func(repo TaskRepositoryInterface) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação:%w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&TaskRepository{db: r.db, tx: tx, dialect: r.dialect}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação:%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+// Operações do lote (POST /tasks:batch)
+const (
+	BatchCreate   = "create"
+	BatchUpdate   = "update" // merge patch, como o PATCH /tasks/{id}
+	BatchDelete   = "delete"
+	BatchComplete = "complete"
+)
+
+// Modos do lote: atomic grava tudo ou nada numa transação; best_effort aplica cada
+// operação de forma independente e segue mesmo se alguma falhar
+const (
+	BatchAtomic     = "atomic"
+	BatchBestEffort = "best_effort"
+)
+
+// maxBatchSize é o máximo de operações por lote
+const maxBatchSize = 100
+
+// ErrNotApplied marca, no modo atomic, as operações desfeitas (ou nem executadas)
+// porque outra operação do lote falhou
+var ErrNotApplied = errors.New("not applied")
+
+// BatchOperation é uma operação do lote. Create é usado em create e Patch em update;
+// ID é obrigatório em update, delete e complete.
+type BatchOperation struct {
+	Op      string
+	ID      string
+	Cascade bool // complete: conclui também as subtasks
+	Create  CreateTaskInput
+	Patch   PatchTaskInput
+}
+
+// BatchResult é o resultado de uma operação, na mesma posição dela no lote.
+// Task é nil em delete e em falha.
+type BatchResult struct {
+	Task *model.Task
+	Err  error
+}
+
+// ------------------------BATCH--------------------------------
+// Batch aplica as operações na ordem. O erro de retorno é só do lote como um todo
+// (lote inválido, falha ao abrir a transação); o de cada operação fica no resultado dela.
+func (s *TaskService) Batch(ctx context.Context, mode string, ops []BatchOperation) ([]BatchResult, error) {
+	if err := validateBatch(mode, ops); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(ops))
+	if mode == BatchBestEffort {
+		for i, op := range ops {
+			results[i] = s.applyBatchOperation(ctx, op)
+		}
+		return results, nil
+	}
+
+	failed := -1
+	err := s.repo.WithinTx(ctx, func(repo repository.TaskRepositoryInterface) error {
+		// Dentro da transação tudo passa pelo repository dela
+		tx := s.withRepo(repo)
+		for i, op := range ops {
+			results[i] = tx.applyBatchOperation(ctx, op)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if failed < 0 {
+		return results, err
+	}
+
+	// Rollback: só a operação que falhou fica com o próprio erro
+	for i := range results {
+		switch {
+		case i < failed:
+			results[i] = BatchResult{Err: &Error{Kind: ErrNotApplied, Message: fmt.Sprintf("rolled back: operation %d failed", failed)}}
+		case i > failed:
+			results[i] = BatchResult{Err: &Error{Kind: ErrNotApplied, Message: fmt.Sprintf("not executed: operation %d failed", failed)}}
+		}
+	}
+	return results, nil
+}
+
+// withRepo devolve uma cópia do service que usa outro repository (o de uma transação)
+func (s *TaskService) withRepo(repo repository.TaskRepositoryInterface) *TaskService {
+	clone := *s
+	clone.repo = repo
+	return &clone
+}
+
+func (s *TaskService) applyBatchOperation(ctx context.Context, op BatchOperation) BatchResult {
+	var (
+		task *model.Task
+		err  error
+	)
+	switch op.Op {
+	case BatchCreate:
+		task, err = s.CreateTask(ctx, op.Create)
+	case BatchUpdate:
+		task, err = s.PatchTask(ctx, op.ID, op.Patch, 0)
+	case BatchDelete:
+		err = s.DeleteTask(ctx, op.ID, 0)
+	case BatchComplete:
+		task, err = s.CompleteTask(ctx, op.ID, op.Cascade, 0)
+	}
+	return BatchResult{Task: task, Err: err}
+}
+
+// validateBatch confere o lote inteiro antes de executar qualquer operação
+func validateBatch(mode string, ops []BatchOperation) error {
+	var errs validationErrors
+	if mode == "" {
+		errs.add("mode", "mode is required")
+	}
+	errs.checkEnum("mode", "batch_mode", mode)
+	switch {
+	case len(ops) == 0:
+		errs.add("operations", "operations is required")
+	case len(ops) > maxBatchSize:
+		errs.add("operations", "too many operations (max %d)", maxBatchSize)
+	}
+
+	for i, op := range ops {
+		field := fmt.Sprintf("operations[%d]", i)
+		if op.Op == "" {
+			errs.add(field+".op", "op is required")
+			continue
+		}
+		if !errs.checkEnum(field+".op", "batch_op", op.Op) {
+			continue
+		}
+		if op.Op != BatchCreate && op.ID == "" {
+			errs.add(field+".id", "id is required for %s", op.Op)
+		}
+	}
+	return errs.err()
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+// O cursor é opaco para o cliente: JSON em base64 url-safe.
+// O formato pode mudar sem quebrar ninguém, desde que o cliente só devolva o que recebeu.
+// Ele guarda a ordenação usada, para não ser reaproveitado com outro sort.
+
+type cursorPayload struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     string   `json:"i"`
+}
+
+func encodeCursor(sort string, c repository.Cursor) string {
+	raw, _ := json.Marshal(cursorPayload{Sort: sort, Values: c.Values, ID: c.ID}) // struct simples, não falha
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor valida o cursor contra a ordenação atual
+func decodeCursor(s string, sort string, fields []repository.SortField) (*repository.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid("cursor", "invalid cursor")
+	}
+
+	var p cursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil || p.ID == "" {
+		return nil, invalid("cursor", "invalid cursor")
+	}
+
+	if p.Sort != sort {
+		return nil, invalid("cursor", "cursor was created with a different sort")
+	}
+
+	cursor := &repository.Cursor{Values: p.Values, ID: p.ID}
+	if err := cursor.Validate(fields); err != nil {
+		return nil, invalid("cursor", "invalid cursor")
+	}
+
+	return cursor, nil
+}
+
+// A busca pagina por offset (relevância não serve de keyset).
+// O cursor guarda o texto buscado para não ser reaproveitado em outra busca.
+
+type searchCursorPayload struct {
+	Query  string `json:"q"`
+	Offset int    `json:"o"`
+}
+
+func encodeSearchCursor(query string, offset int) string {
+	raw, _ := json.Marshal(searchCursorPayload{Query: query, Offset: offset}) // struct simples, não falha
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeSearchCursor(s string, query string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, invalid("cursor", "invalid cursor")
+	}
+
+	var p searchCursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil || p.Offset < 0 {
+		return 0, invalid("cursor", "invalid cursor")
+	}
+
+	if p.Query != query {
+		return 0, invalid("cursor", "cursor was created for a different search")
+	}
+
+	return p.Offset, nil
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+// ------------------------DEPENDENCIES--------------------------------
+// AddDependency registra que taskID está bloqueada por blockerID.
+// As duas tasks precisam existir e a nova aresta não pode fechar um ciclo.
+func (s *TaskService) AddDependency(ctx context.Context, taskID, blockerID string) (*model.TaskDependencies, error) {
+	if blockerID == "" {
+		return nil, invalid("blocker_id", "blocker_id is required")
+	}
+	if taskID == blockerID {
+		return nil, invalid("blocker_id", "a task cannot depend on itself")
+	}
+
+	_, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		return nil, tx.addDependency(ctx, taskID, blockerID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetDependencies(ctx, taskID)
+}
+
+// addDependency confere e grava a aresta dentro da transação do AddDependency. O grafo
+// inteiro fica travado até o commit (LockDependencies), então duas arestas que juntas
+// fechariam um ciclo nunca são checadas ao mesmo tempo. As duas tasks também ficam
+// travadas, para não irem para a lixeira no meio.
+func (s *TaskService) addDependency(ctx context.Context, taskID, blockerID string) error {
+	if err := s.repo.LockDependencies(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.lockTask(ctx, taskID); err != nil {
+		return err
+	}
+	if _, err := s.lockTask(ctx, blockerID); err != nil {
+		// A task da URL existe; o que falta é o bloqueio informado no corpo
+		if errors.Is(err, ErrNotFound) {
+			return invalid("blocker_id", "blocker task not found")
+		}
+		return err
+	}
+
+	deps, err := s.repo.FindDependencies(ctx)
+	if err != nil {
+		return fmt.Errorf("Error loading dependencies: %w", err)
+	}
+
+	blockers := blockersByTask(deps)
+	if slices.Contains(blockers[taskID], blockerID) {
+		return conflict("dependency already exists")
+	}
+	// taskID -> blockerID fecha um ciclo se taskID já é (direta ou indiretamente) bloqueio de blockerID
+	if reaches(blockers, blockerID, taskID) {
+		return conflict("dependency would create a cycle")
+	}
+
+	return s.repo.AddDependency(ctx, taskID, blockerID)
+}
+
+// RemoveDependency desfaz o bloqueio de taskID por blockerID, com o grafo travado como no AddDependency
+func (s *TaskService) RemoveDependency(ctx context.Context, taskID, blockerID string) error {
+	_, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		if err := tx.repo.LockDependencies(ctx); err != nil {
+			return nil, err
+		}
+		if _, err := tx.lockTask(ctx, taskID); err != nil {
+			return nil, err
+		}
+
+		deps, err := tx.repo.FindDependencies(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading dependencies: %w", err)
+		}
+		if !slices.Contains(deps, model.Dependency{TaskID: taskID, BlockerID: blockerID}) {
+			return nil, notFound("dependency not found")
+		}
+
+		return nil, tx.repo.RemoveDependency(ctx, taskID, blockerID)
+	})
+	return err
+}
+
+// GetDependencies devolve as listas blocked_by e blocking da task
+func (s *TaskService) GetDependencies(ctx context.Context, id string) (*model.TaskDependencies, error) {
+	if _, err := s.findTask(ctx, id); err != nil {
+		return nil, err
+	}
+
+	blockedBy, err := s.repo.FindBlockers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	blocking, err := s.repo.FindBlocking(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sempre listas no JSON, nunca null
+	result := &model.TaskDependencies{BlockedBy: []model.Task{}, Blocking: []model.Task{}}
+	result.BlockedBy = append(result.BlockedBy, blockedBy...)
+	result.Blocking = append(result.Blocking, blocking...)
+
+	if err := s.decorate(ctx, append(model.TaskPtrs(result.BlockedBy), model.TaskPtrs(result.Blocking)...)...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkNotBlocked falha se alguma das tasks tem bloqueio em aberto.
+// completing são as tasks sendo concluídas na mesma operação (cascade): não contam como bloqueio.
+func (s *TaskService) checkNotBlocked(ctx context.Context, ids []string, completing map[string]bool) error {
+	for _, id := range ids {
+		blockers, err := s.repo.FindBlockers(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		pending := 0
+		for _, blocker := range blockers {
+			if !model.IsClosed(blocker.Status) && !completing[blocker.ID] {
+				pending++
+			}
+		}
+		if pending > 0 {
+			return conflict("task %s is blocked by %d pending tasks", id, pending)
+		}
+	}
+	return nil
+}
+
+// ------------------------WORK PLAN--------------------------------
+// WorkPlan ordena as tasks em aberto topologicamente (algoritmo de Kahn, por camadas):
+// primeiro as que já podem ser feitas (ready), depois as que elas liberam, e assim por diante.
+// Dentro de cada camada: maior prioridade, prazo mais próximo, mais antiga.
+// Carrega todas as tasks em aberto de uma vez; pensado para o volume de um time, não para milhões.
+func (s *TaskService) WorkPlan(ctx context.Context) (*model.WorkPlan, error) {
+	tasks, err := s.repo.FindAll(ctx, model.TaskFilter{Statuses: openStatuses()}, repository.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing tasks: %w", err)
+	}
+	deps, err := s.repo.FindDependencies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading dependencies: %w", err)
+	}
+
+	byID := make(map[string]*model.Task, len(tasks))
+	for i := range tasks {
+		byID[tasks[i].ID] = &tasks[i]
+	}
+
+	// Só contam as arestas entre tasks em aberto: bloqueio concluído (ou na lixeira) já não bloqueia
+	openBlockers := map[string][]string{}
+	unlocks := map[string][]string{}
+	for _, d := range deps {
+		if byID[d.TaskID] == nil || byID[d.BlockerID] == nil {
+			continue
+		}
+		openBlockers[d.TaskID] = append(openBlockers[d.TaskID], d.BlockerID)
+		unlocks[d.BlockerID] = append(unlocks[d.BlockerID], d.TaskID)
+	}
+
+	remaining := make(map[string]int, len(tasks))
+	var layer []*model.Task
+	for i := range tasks {
+		id := tasks[i].ID
+		remaining[id] = len(openBlockers[id])
+		if remaining[id] == 0 {
+			layer = append(layer, &tasks[i])
+		}
+	}
+
+	ordered := make([]*model.Task, 0, len(tasks))
+	for len(layer) > 0 {
+		slices.SortFunc(layer, comparePlan)
+		ordered = append(ordered, layer...)
+
+		var next []*model.Task
+		for _, task := range layer {
+			for _, id := range unlocks[task.ID] {
+				remaining[id]--
+				if remaining[id] == 0 {
+					next = append(next, byID[id])
+				}
+			}
+		}
+		layer = next
+	}
+
+	// Ciclo no banco (não deveria existir, o AddDependency rejeita): as tasks presas vão para o fim
+	if len(ordered) < len(tasks) {
+		var stuck []*model.Task
+		for i := range tasks {
+			if remaining[tasks[i].ID] > 0 {
+				stuck = append(stuck, &tasks[i])
+			}
+		}
+		slices.SortFunc(stuck, comparePlan)
+		ordered = append(ordered, stuck...)
+	}
+
+	if err := s.decorate(ctx, ordered...); err != nil {
+		return nil, err
+	}
+
+	plan := &model.WorkPlan{Items: make([]model.WorkItem, 0, len(ordered))}
+	for _, task := range ordered {
+		blockedBy := append([]string{}, openBlockers[task.ID]...)
+		slices.Sort(blockedBy)
+		plan.Items = append(plan.Items, model.WorkItem{
+			Task:      *task,
+			Ready:     len(blockedBy) == 0,
+			BlockedBy: blockedBy,
+		})
+	}
+	return plan, nil
+}
+
+// comparePlan: prioridade maior primeiro, depois prazo mais próximo (sem prazo por último),
+// depois a mais antiga, e o id para a ordem ser determinística
+func comparePlan(a, b *model.Task) int {
+	if c := cmp.Compare(model.PriorityRank(b.Priority), model.PriorityRank(a.Priority)); c != 0 {
+		return c
+	}
+	switch {
+	case a.DueAt != nil && b.DueAt != nil:
+		if c := a.DueAt.Compare(*b.DueAt); c != 0 {
+			return c
+		}
+	case a.DueAt != nil:
+		return -1
+	case b.DueAt != nil:
+		return 1
+	}
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// blockersByTask monta a lista de adjacência task -> bloqueios
+func blockersByTask(deps []model.Dependency) map[string][]string {
+	blockers := make(map[string][]string, len(deps))
+	for _, d := range deps {
+		blockers[d.TaskID] = append(blockers[d.TaskID], d.BlockerID)
+	}
+	return blockers
+}
+
+// reaches diz se, seguindo os bloqueios a partir de from, dá para chegar em target (DFS)
+func reaches(blockers map[string][]string, from, target string) bool {
+	visited := map[string]bool{}
+	stack := []string{from}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if id == target {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, blockers[id]...)
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+// Tipos de erro de domínio. O handler escolhe o status HTTP com errors.Is;
+// qualquer outro erro (banco fora do ar etc.) é tratado como falha interna.
+var (
+	ErrNotFound     = errors.New("not found")           // a task (ou dependência) não existe
+	ErrConflict     = errors.New("conflict")            // a operação não cabe no estado atual
+	ErrValidation   = errors.New("validation failed")   // a entrada é inválida
+	ErrPrecondition = errors.New("precondition failed") // a versão informada (If-Match) não é a atual
+)
+
+// FieldError aponta um campo (do corpo, da query ou header) e o que há de errado com ele
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error é um erro de domínio: Message vai para o cliente e Kind é um dos Err* acima.
+// Erros de validação trazem em Fields os campos inválidos.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []FieldError
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+// versionError traduz o ErrVersionConflict do repository: a task mudou entre a leitura
+// e a escrita. Com versão esperada (If-Match) é falha de pré-condição; sem ela, conflito.
+func versionError(err error, version int64) error {
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		return err
+	}
+	if version > 0 {
+		return preconditionFailed("task version %d is no longer current", version)
+	}
+	return conflict("task was modified by another request: try again")
+}
+
+func notFound(format string, args ...any) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func preconditionFailed(format string, args ...any) error {
+	return &Error{Kind: ErrPrecondition, Message: fmt.Sprintf(format, args...)}
+}
+
+// invalid cria um erro de validação do campo field ("" quando não há um campo específico)
+func invalid(field, format string, args ...any) error {
+	e := &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+	if field != "" {
+		e.Fields = []FieldError{{Field: field, Message: e.Message}}
+	}
+	return e
+}
+
+// renameField troca o campo de um erro de validação; serve para regras
+// compartilhadas entre o corpo e a query (tags no corpo, tag na listagem)
+func renameField(err error, field string) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	renamed := *e
+	renamed.Fields = make([]FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		renamed.Fields[i] = FieldError{Field: field, Message: f.Message}
+	}
+	return &renamed
+}
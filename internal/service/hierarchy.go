@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+// maxDepth é o número máximo de níveis da hierarquia (a task raiz é o nível 1)
+const maxDepth = 5
+
+// validateParent confere se parentID pode ser o pai de task (nil = task nova):
+// o pai precisa existir, não pode ser a própria task nem um descendente dela
+// (ciclo), e a árvore resultante não pode passar de maxDepth níveis.
+// Chamado dentro de inTx, o pai fica travado até o commit e não sai da árvore no meio.
+func (s *TaskService) validateParent(ctx context.Context, task *model.Task, parentID string) error {
+	parent, err := s.repo.FindByIDForUpdate(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return invalid("parent_id", "parent task not found")
+	}
+
+	if task != nil && parentID == task.ID {
+		return invalid("parent_id", "a task cannot be its own parent")
+	}
+
+	// Sobe a partir do pai: se passar pela task, o novo pai é descendente dela
+	depth := 1
+	for current := parent; current.ParentID != nil; depth++ {
+		if task != nil && *current.ParentID == task.ID {
+			return conflict("parent would create a cycle")
+		}
+		if depth > maxDepth {
+			return conflict("task hierarchy exceeds max depth (%d)", maxDepth)
+		}
+
+		next, err := s.repo.FindByID(ctx, *current.ParentID)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			// Pai na lixeira: a cadeia para aqui
+			break
+		}
+		current = next
+	}
+
+	// A task leva junto as subtasks dela
+	height := 1
+	if task != nil {
+		if height, err = s.subtreeHeight(ctx, task.ID); err != nil {
+			return err
+		}
+	}
+
+	if depth+height > maxDepth {
+		return conflict("task hierarchy exceeds max depth (%d)", maxDepth)
+	}
+	return nil
+}
+
+// subtreeHeight conta os níveis da task para baixo (1 = sem subtasks)
+func (s *TaskService) subtreeHeight(ctx context.Context, id string) (int, error) {
+	height := 0
+	level := []string{id}
+	for len(level) > 0 && height <= maxDepth {
+		height++
+
+		var next []string
+		for _, parentID := range level {
+			children, err := s.children(ctx, parentID)
+			if err != nil {
+				return 0, err
+			}
+			for _, child := range children {
+				next = append(next, child.ID)
+			}
+		}
+		level = next
+	}
+	return height, nil
+}
+
+// openDescendants devolve as subtasks abertas em qualquer nível, nível a nível
+// (os filhos antes dos netos)
+func (s *TaskService) openDescendants(ctx context.Context, id string) ([]model.Task, error) {
+	var open []model.Task
+
+	level := []string{id}
+	for depth := 0; len(level) > 0 && depth < maxDepth; depth++ {
+		var next []string
+		for _, parentID := range level {
+			children, err := s.children(ctx, parentID)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				if !model.IsClosed(child.Status) {
+					open = append(open, child)
+				}
+				next = append(next, child.ID)
+			}
+		}
+		level = next
+	}
+	return open, nil
+}
+
+// children lista todas as subtasks ativas diretas da task, sem paginação
+func (s *TaskService) children(ctx context.Context, id string) ([]model.Task, error) {
+	children, err := s.repo.FindAll(ctx, model.TaskFilter{ParentID: id}, repository.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing subtasks: %w", err)
+	}
+	return children, nil
+}
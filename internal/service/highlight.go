@@ -0,0 +1,124 @@
+package service
+
+import (
+	"html"
+	"strings"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// snippetRadius é quantos caracteres de contexto ficam de cada lado do primeiro termo
+// encontrado na descrição
+const snippetRadius = 80
+
+// highlight monta os trechos destacados de um resultado da busca
+func highlight(task model.Task, terms []string) model.SearchHighlight {
+	return model.SearchHighlight{
+		Title:       markTerms(task.Title, terms),
+		Description: markTerms(snippet(task.Description, terms), terms),
+	}
+}
+
+// snippet recorta a descrição em volta do primeiro termo encontrado.
+// Descrições curtas voltam inteiras; sem termo encontrado, volta o começo.
+func snippet(text string, terms []string) string {
+	runes := []rune(text)
+	if len(runes) <= 2*snippetRadius {
+		return text
+	}
+
+	lower := []rune(strings.ToLower(text))
+	first := -1
+	for _, term := range terms {
+		if i := indexRunes(lower, []rune(term)); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	start := max(first-snippetRadius, 0)
+	end := min(first+snippetRadius, len(runes))
+
+	out := string(runes[start:end])
+	if start > 0 {
+		out = "…" + out
+	}
+	if end < len(runes) {
+		out += "…"
+	}
+	return out
+}
+
+// markTerms escapa o texto como HTML e envolve cada ocorrência dos termos em <mark>,
+// sem diferenciar maiúsculas de minúsculas
+func markTerms(text string, terms []string) string {
+	if text == "" {
+		return ""
+	}
+
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+
+	// ToLower pode mudar o número de runes em alguns alfabetos; nesse caso só escapa
+	if len(lower) != len(runes) {
+		return html.EscapeString(text)
+	}
+
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		t := []rune(term)
+		if len(t) == 0 {
+			continue
+		}
+		for from := 0; from <= len(lower)-len(t); {
+			i := indexRunes(lower[from:], t)
+			if i < 0 {
+				break
+			}
+			for k := from + i; k < from+i+len(t); k++ {
+				marked[k] = true
+			}
+			from += i + len(t)
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) + 16)
+	open := false
+	for i, r := range runes {
+		if marked[i] && !open {
+			b.WriteString("<mark>")
+			open = true
+		}
+		if !marked[i] && open {
+			b.WriteString("</mark>")
+			open = false
+		}
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
+
+func indexRunes(haystack, needle []rune) int {
+	if len(needle) == 0 || len(needle) > len(haystack) {
+		return -1
+	}
+	for i := 0; i <= len(haystack)-len(needle); i++ {
+		match := true
+		for j := range needle {
+			if haystack[i+j] != needle[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Ações do ciclo de vida, usadas nos endpoints PATCH /tasks/{id}/{ação}
+const (
+	ActionStart    = "start"
+	ActionBlock    = "block"
+	ActionUnblock  = "unblock"
+	ActionComplete = "complete"
+	ActionCancel   = "cancel"
+	ActionReopen   = "reopen"
+	ActionArchive  = "archive"
+)
+
+type transition struct {
+	action string
+	from   []string
+	to     string
+}
+
+// transitions é a máquina de estados das tasks: toda mudança de status,
+// seja por ação ou pelo PUT, precisa estar nesta tabela. archived é final.
+var transitions = []transition{
+	{ActionStart, []string{model.StatusPending, model.StatusBlocked}, model.StatusInProgress},
+	{ActionBlock, []string{model.StatusPending, model.StatusInProgress}, model.StatusBlocked},
+	{ActionUnblock, []string{model.StatusBlocked}, model.StatusPending},
+	{ActionComplete, []string{model.StatusPending, model.StatusInProgress}, model.StatusCompleted},
+	{ActionCancel, []string{model.StatusPending, model.StatusInProgress, model.StatusBlocked}, model.StatusCancelled},
+	{ActionReopen, []string{model.StatusCompleted, model.StatusCancelled}, model.StatusPending},
+	{ActionArchive, []string{model.StatusCompleted, model.StatusCancelled}, model.StatusArchived},
+}
+
+// availableTransitions lista as ações permitidas a partir do status, na ordem da tabela
+func availableTransitions(status string) []string {
+	actions := []string{}
+	for _, t := range transitions {
+		if slices.Contains(t.from, status) {
+			actions = append(actions, t.action)
+		}
+	}
+	return actions
+}
+
+// findTransition procura a transição de uma ação
+func findTransition(action string) (transition, bool) {
+	for _, t := range transitions {
+		if t.action == action {
+			return t, true
+		}
+	}
+	return transition{}, false
+}
+
+// canTransition diz se a tabela permite ir de from para to
+func canTransition(from, to string) bool {
+	for _, t := range transitions {
+		if t.to == to && slices.Contains(t.from, from) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxActorLength é o tamanho máximo de quem reabriu (coluna reopened_by)
+const maxActorLength = 100
+
+// setStatus muda o status mantendo os campos derivados do ciclo de vida:
+// concluir grava completed_at; voltar a abrir uma task encerrada limpa completed_at
+// e registra quando e por quem (by nil = não informado) ela foi reaberta. Desbloquear
+// não é reabrir: a task não estava encerrada e os campos de reabertura ficam como estão.
+func setStatus(task *model.Task, to string, now time.Time, by *string) {
+	if to == task.Status {
+		return
+	}
+	switch {
+	case to == model.StatusCompleted:
+		task.CompletedAt = &now
+	case to == model.StatusPending && model.IsClosed(task.Status):
+		task.CompletedAt = nil
+		task.ReopenedAt = &now
+		task.ReopenedBy = by
+	}
+	task.Status = to
+}
+
+// ------------------------TRANSITION TASK--------------------------------
+// TransitionTask aplica uma ação do ciclo de vida. complete segue as regras
+// do CompleteTask (sem cascade); as demais só mudam o status.
+// version funciona como no PatchTask.
+func (s *TaskService) TransitionTask(ctx context.Context, id, action string, version int64) (*model.Task, error) {
+	if action == ActionComplete {
+		return s.CompleteTask(ctx, id, false, version)
+	}
+	return s.transition(ctx, id, action, nil, version)
+}
+
+// ------------------------REOPEN TASK--------------------------------
+// ReopenTask volta a task para pending e registra quem reabriu (by vazio = não informado)
+func (s *TaskService) ReopenTask(ctx context.Context, id, by string, version int64) (*model.Task, error) {
+	by = strings.TrimSpace(by)
+	if utf8.RuneCountInString(by) > maxActorLength {
+		return nil, invalid("X-User", "reopened_by is too long (max %d)", maxActorLength)
+	}
+
+	var actor *string
+	if by != "" {
+		actor = &by
+	}
+	return s.transition(ctx, id, ActionReopen, actor, version)
+}
+
+// transition aplica a ação da tabela; by só é usado ao reabrir
+func (s *TaskService) transition(ctx context.Context, id, action string, by *string, version int64) (*model.Task, error) {
+	t, ok := findTransition(action)
+	if !ok {
+		return nil, invalid("", "unknown transition %q", action)
+	}
+
+	task, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		return tx.applyTransition(ctx, id, action, t, by, version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.decorate(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// applyTransition muda o status dentro da transação do transition
+func (s *TaskService) applyTransition(ctx context.Context, id, action string, t transition, by *string, version int64) (*model.Task, error) {
+	task, err := s.lockTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(t.from, task.Status) {
+		return nil, conflict("cannot %s a task with status %q", action, task.Status)
+	}
+	if err := s.checkStatusChange(ctx, task, t.to); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	setStatus(task, t.to, now, by)
+	task.UpdatedAt = now
+	if err := s.repo.Update(ctx, task); err != nil {
+		return nil, versionError(err, version)
+	}
+	return task, nil
+}
+
+// checkStatusChange aplica as regras que dependem de outras tasks, além da tabela:
+// só encerra quem não tem subtasks em aberto, só conclui quem não tem bloqueio
+// pendente e só reabre (ou volta a abrir) quem não está dentro de uma task encerrada.
+func (s *TaskService) checkStatusChange(ctx context.Context, task *model.Task, to string) error {
+	closing := model.IsClosed(to) && !model.IsClosed(task.Status)
+	opening := !model.IsClosed(to) && model.IsClosed(task.Status)
+
+	if closing {
+		open, err := s.openDescendants(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if len(open) > 0 {
+			return conflict("task has %d open subtasks: close them first", len(open))
+		}
+	}
+
+	if to == model.StatusCompleted {
+		if err := s.checkNotBlocked(ctx, []string{task.ID}, nil); err != nil {
+			return err
+		}
+	}
+
+	if opening && task.ParentID != nil {
+		parent, err := s.repo.FindByID(ctx, *task.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent != nil && model.IsClosed(parent.Status) {
+			return conflict("parent task is closed: reopen it first")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// Optional é um campo de JSON Merge Patch (RFC 7396), que tem três estados:
+// ausente (Present false), null explícito (Null true) ou um valor.
+type Optional[T any] struct {
+	Present bool
+	Null    bool
+	Value   T
+}
+
+// Some cria um campo presente com valor
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Present: true, Value: v}
+}
+
+// Null cria um campo presente com null
+func Null[T any]() Optional[T] {
+	return Optional[T]{Present: true, Null: true}
+}
+
+// UnmarshalJSON só é chamado quando a chave aparece no corpo: ausente fica no zero value
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Present = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// Valued diz se o campo veio com valor (nem ausente, nem null)
+func (o Optional[T]) Valued() bool {
+	return o.Present && !o.Null
+}
+
+// optionalField é como o validador enxerga um Optional[T] sem saber o T
+type optionalField interface {
+	state() (present, null bool, value reflect.Value)
+}
+
+func (o Optional[T]) state() (bool, bool, reflect.Value) {
+	return o.Present, o.Null, reflect.ValueOf(o.Value)
+}
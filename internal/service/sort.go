@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+// parseSort interpreta o parâmetro sort: campos separados por vírgula,
+// "-" na frente para decrescente (ex.: "-priority,created_at").
+// Só campos da whitelist do repository são aceitos.
+// Retorna também a forma canônica, usada para amarrar o cursor à ordenação.
+func parseSort(raw string) ([]repository.SortField, string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return repository.DefaultSort, formatSort(repository.DefaultSort), nil
+	}
+
+	var (
+		fields []repository.SortField
+		seen   = map[string]bool{}
+	)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+
+		field := repository.SortField{Name: part}
+		if strings.HasPrefix(part, "-") {
+			field = repository.SortField{Name: part[1:], Desc: true}
+		}
+
+		if !repository.IsSortable(field.Name) {
+			return nil, "", invalid("sort", "invalid sort field: %q", field.Name)
+		}
+		if seen[field.Name] {
+			return nil, "", invalid("sort", "duplicated sort field: %q", field.Name)
+		}
+		seen[field.Name] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, formatSort(fields), nil
+}
+
+func formatSort(fields []repository.SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Name
+		if f.Desc {
+			parts[i] = "-" + f.Name
+		}
+	}
+	return strings.Join(parts, ",")
+}
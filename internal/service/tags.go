@@ -0,0 +1,64 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limites das tags de uma task
+const (
+	maxTagLength   = 50
+	maxTagsPerTask = 20
+)
+
+// normalizeTags aplica as regras de tag do projeto:
+// minúsculas, espaços nas pontas removidos, espaços internos viram "-",
+// só letras, dígitos e "-", "_", ".", ":". Repetidas são descartadas e o
+// resultado sai em ordem alfabética. nil continua nil ("não informado").
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, raw := range tags {
+		tag, err := normalizeTag(raw)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+
+	if len(out) > maxTagsPerTask {
+		return nil, invalid("tags", "too many tags (max %d)", maxTagsPerTask)
+	}
+
+	sort.Strings(out)
+	return out, nil
+}
+
+func normalizeTag(raw string) (string, error) {
+	tag := strings.Join(strings.Fields(strings.ToLower(raw)), "-")
+	if tag == "" {
+		return "", invalid("tags", "tag must not be empty")
+	}
+	if utf8.RuneCountInString(tag) > maxTagLength {
+		return "", invalid("tags", "tag %q is too long (max %d)", tag, maxTagLength)
+	}
+
+	for _, r := range tag {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.:", r) {
+			continue
+		}
+		return "", invalid("tags", "tag %q has invalid character %q", tag, r)
+	}
+
+	return tag, nil
+}
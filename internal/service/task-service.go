@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/DinizJ/desafio/internal/model"
@@ -17,46 +18,175 @@ import (
 // Isso facilita testes unitários com mocks e torna o código mais flexível
 
 type TaskService struct {
-	repo repository.TaskRepositoryInterface
+	repo  repository.TaskRepositoryInterface
+	cfg   Config
+	clock func() time.Time // nil = time.Now; os testes fixam o "agora" do overdue
+	// importing aceita prazos vencidos: a task importada pode vir de outro ambiente já atrasada
+	importing bool
+}
+
+// Config reúne os parâmetros ajustáveis do service.
+// Campos zerados usam os valores de DefaultConfig.
+type Config struct {
+	DefaultPageSize int // itens por página quando o cliente não manda limit
+	MaxPageSize     int // limite máximo aceito; pedidos maiores são reduzidos a ele
+}
+
+func DefaultConfig() Config {
+	return Config{
+		DefaultPageSize: 20,
+		MaxPageSize:     100,
+	}
 }
 
 // ------------------------CREATE TASK--------------------------------
 // Adjust Layers
-func NewTaskService(repo repository.TaskRepositoryInterface) *TaskService {
-	return &TaskService{repo: repo}
+func NewTaskService(repo repository.TaskRepositoryInterface, cfg Config) *TaskService {
+	return &TaskService{repo: repo, cfg: cfg}
+}
+
+// now é o instante de referência para prazos (overdue, upcoming)
+func (s *TaskService) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// dueAtNow é o "agora" do validateDueAt; na importação é zero, e prazo vencido é aceito
+func (s *TaskService) dueAtNow() time.Time {
+	if s.importing {
+		return time.Time{}
+	}
+	return s.now()
+}
+
+// decorate preenche os campos calculados (Overdue, Progress) antes de a task sair do service.
+// O progresso de todas as tasks vem de uma única consulta ao repository.
+func (s *TaskService) decorate(ctx context.Context, tasks ...*model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	now := s.now()
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		task.Overdue = task.IsOverdue(now)
+		task.Transitions = availableTransitions(task.Status)
+		ids[i] = task.ID
+	}
+
+	stats, err := s.repo.ChildStats(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("Error loading subtasks: %w", err)
+	}
+	for _, task := range tasks {
+		task.Progress = nil
+		if c, ok := stats[task.ID]; ok {
+			progress := c.Progress()
+			task.Progress = &progress
+		}
+	}
+	return nil
 }
 
-func (s *TaskService) CreateTask(ctx context.Context, title string, description string) (*model.Task, error) {
+// pageSize resolve o limit pedido pelo cliente com base na configuração
+func (s *TaskService) pageSize(limit int) int {
+	defaults := DefaultConfig()
 
-	if title == "" {
-		return nil, errors.New("title is required")
+	def, max := s.cfg.DefaultPageSize, s.cfg.MaxPageSize
+	if def <= 0 {
+		def = defaults.DefaultPageSize
+	}
+	if max <= 0 {
+		max = defaults.MaxPageSize
 	}
 
-	if len(title) > 255 {
-		return nil, errors.New("title is too long(max 255)")
+	if limit <= 0 {
+		limit = def
+	}
+	if limit > max {
+		limit = max
+	}
+	return limit
+}
+
+// CreateTaskInput são os campos aceitos na criação (corpo do POST /tasks)
+type CreateTaskInput struct {
+	Title       string     `json:"title" validate:"required,max=255"`
+	Description string     `json:"description" validate:"max=10000"`
+	DueAt       *time.Time `json:"due_at"`                      // opcional, RFC 3339
+	Tags        []string   `json:"tags"`                        // normalizadas por normalizeTags
+	ParentID    string     `json:"parent_id" validate:"max=36"` // vazio = task raiz
+}
+
+func (s *TaskService) CreateTask(ctx context.Context, in CreateTaskInput) (*model.Task, error) {
+	var errs validationErrors
+	errs.checkStruct(in)
+	errs.addErr(validateDueAt(in.DueAt, s.now()))
+
+	tags, err := normalizeTags(in.Tags)
+	errs.addErr(err)
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var parentID *string
+	if in.ParentID != "" {
+		parentID = &in.ParentID
 	}
 
 	//Cria a TASK
 	task := &model.Task{
 		ID:          uuid.New().String(), // Gera UUID
-		Title:       title,
-		Description: description,
+		ParentID:    parentID,
+		Title:       in.Title,
+		Description: in.Description,
 		Status:      model.StatusPending,
 		Priority:    model.PriorityMedium, // Default
+		DueAt:       in.DueAt,
+		Tags:        tags,
+		Version:     1,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	//Salva no banco pelo Repository
-	if err := s.repo.Save(ctx, task); err != nil {
+	//Salva no banco pelo Repository, na mesma transação que conferiu o pai
+	_, err = s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		if parentID != nil {
+			if err := tx.validateParent(ctx, nil, *parentID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, tx.repo.Save(ctx, task)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// Task nova não tem subtasks nem está atrasada: só as transições precisam ser calculadas
+	task.Transitions = availableTransitions(task.Status)
 	return task, nil
 }
 
 // ------------------------GET TASK--------------------------------
 func (s *TaskService) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := s.findTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.decorate(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// findTask busca a task ativa sem os campos calculados, para uso interno
+func (s *TaskService) findTask(ctx context.Context, id string) (*model.Task, error) {
 
 	//Valida se id é uuid valido
 	task, err := s.repo.FindByID(ctx, id)
@@ -66,116 +196,772 @@ func (s *TaskService) GetTask(ctx context.Context, id string) (*model.Task, erro
 
 	// Repo retorna nil se não encontrou
 	if task == nil {
-		return nil, errors.New("task not found")
+		return nil, notFound("task not found")
+	}
+
+	return task, nil
+}
+
+// lockTask é o findTask de quem vai alterar a task: chamado dentro de inTx, a task fica
+// travada até o fim da transação e requisições concorrentes sobre ela esperam a vez
+func (s *TaskService) lockTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := s.repo.FindByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, notFound("task not found")
+	}
+	return task, nil
+}
+
+// AnyVersion é o version de If-Match: *: qualquer versão serve, mas a task precisa existir
+const AnyVersion int64 = -2
+
+// checkVersion confere a versão que o cliente leu (If-Match) com a da task travada;
+// 0 (sem If-Match) e AnyVersion aceitam qualquer versão
+func checkVersion(task *model.Task, version int64) error {
+	if version != 0 && version != AnyVersion && task.Version != version {
+		return preconditionFailed("task version is %d, not %d", task.Version, version)
 	}
+	return nil
+}
 
+// inTx roda um fluxo de leitura e escrita (unit of work) numa transação, com uma cópia
+// do service presa a ela: se fn devolver erro, nada do que gravou fica. Dentro de outro
+// inTx (ex.: lote atômico) usa a mesma transação.
+func (s *TaskService) inTx(ctx context.Context, fn func(tx *TaskService) (*model.Task, error)) (*model.Task, error) {
+	var task *model.Task
+	err := s.repo.WithinTx(ctx, func(repo repository.TaskRepositoryInterface) error {
+		var err error
+		task, err = fn(s.withRepo(repo))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 	return task, nil
 }
 
 // Marca como concluída
 
 // ------------------------COMPLETE TASK--------------------------------
-func (s *TaskService) CompleteTask(ctx context.Context, id string) (*model.Task, error) {
-	task, err := s.GetTask(ctx, id)
+// CompleteTask recusa concluir uma task com subtasks em aberto, a não ser com cascade,
+// que conclui também todas as subtasks (em qualquer nível) ainda abertas.
+// version funciona como no PatchTask.
+func (s *TaskService) CompleteTask(ctx context.Context, id string, cascade bool, version int64) (*model.Task, error) {
+	task, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		return tx.completeTask(ctx, id, cascade, version)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.decorate(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// completeTask conclui a task (e as subtasks, no cascade) dentro da transação do CompleteTask
+func (s *TaskService) completeTask(ctx context.Context, id string, cascade bool, version int64) (*model.Task, error) {
+	task, err := s.lockTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		return nil, err
+	}
+
 	// CORREÇÃO: A lógica estava invertida. Se o status JÁ É "completed",
 	// devemos retornar erro. A condição anterior verificava se era DIFERENTE (!= ),
 	// o que causava o comportamento oposto ao esperado.
 	if task.Status == model.StatusCompleted {
-		return nil, errors.New("task already completed")
+		return nil, conflict("task already completed")
+	}
+	if !canTransition(task.Status, model.StatusCompleted) {
+		return nil, conflict("cannot complete a task with status %q", task.Status)
 	}
 
-	task.Status = model.StatusCompleted
-	task.UpdatedAt = time.Now()
+	open, err := s.openDescendants(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 && !cascade {
+		return nil, conflict("task has %d open subtasks: complete them first or use cascade", len(open))
+	}
+	// O cascade segue a máquina de estados: subtask bloqueada não vai direto para completed
+	for _, child := range open {
+		if !canTransition(child.Status, model.StatusCompleted) {
+			return nil, conflict("subtask %s has status %q and cannot be completed", child.ID, child.Status)
+		}
+	}
 
-	if err := s.repo.Update(ctx, task); err != nil {
+	// Nenhuma das tasks concluídas aqui pode ter bloqueio pendente fora da própria operação
+	completing := map[string]bool{task.ID: true}
+	ids := []string{task.ID}
+	for _, child := range open {
+		completing[child.ID] = true
+		ids = append(ids, child.ID)
+	}
+	if err := s.checkNotBlocked(ctx, ids, completing); err != nil {
 		return nil, err
 	}
 
+	// Das subtasks mais profundas para a raiz, todas com o mesmo completed_at
+	now := time.Now()
+	for i := len(open) - 1; i >= 0; i-- {
+		setStatus(&open[i], model.StatusCompleted, now, nil)
+		open[i].UpdatedAt = now
+		if err := s.repo.Update(ctx, &open[i]); err != nil {
+			return nil, versionError(err, 0)
+		}
+	}
+
+	setStatus(task, model.StatusCompleted, now, nil)
+	task.UpdatedAt = now
+
+	if err := s.repo.Update(ctx, task); err != nil {
+		return nil, versionError(err, version)
+	}
 	return task, nil
 }
 
 // ------------------------DELETE TASK--------------------------------
-func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
+// DeleteTask manda a task para a lixeira; version funciona como no PatchTask.
+// Task com subtasks em aberto ou que bloqueia tasks em aberto não vai para a lixeira:
+// as subtasks ficariam dentro de uma task apagada e os bloqueios sumiriam sem aviso.
+func (s *TaskService) DeleteTask(ctx context.Context, id string, version int64) error {
+
+	_, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		task, err := tx.lockTask(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVersion(task, version); err != nil {
+			return nil, err
+		}
+		if err := tx.checkDeletable(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, versionError(tx.repo.Delete(ctx, id), version)
+	})
+	return err
+}
 
-	task, err := s.repo.FindByID(ctx, id)
+// checkDeletable falha se a task tem subtasks em aberto (em qualquer nível) ou bloqueia
+// alguma task em aberto. Com a task travada, não entra subtask nem dependência nova no meio.
+func (s *TaskService) checkDeletable(ctx context.Context, id string) error {
+	open, err := s.openDescendants(ctx, id)
 	if err != nil {
 		return err
 	}
-
-	if task == nil {
-		return errors.New("task not found")
+	if len(open) > 0 {
+		return conflict("task has %d open subtasks: close or delete them first", len(open))
 	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	blocking, err := s.repo.FindBlocking(ctx, id)
+	if err != nil {
 		return err
 	}
-
+	dependents := 0
+	for _, task := range blocking {
+		if !model.IsClosed(task.Status) {
+			dependents++
+		}
+	}
+	if dependents > 0 {
+		return conflict("task blocks %d open tasks: remove the dependencies first", dependents)
+	}
 	return nil
 }
 
-// ------------------------UPDATE TASK--------------------------------
-func (s *TaskService) UpdateTask(
-	ctx context.Context, id string, title string, description string, status string, priority string,
-) (*model.Task, error) {
-	task, err := s.GetTask(ctx, id)
+// ------------------------TRASH--------------------------------
+func (s *TaskService) ListTrash(ctx context.Context) ([]model.Task, error) {
+
+	tasks, err := s.repo.FindDeleted(ctx)
 	if err != nil {
+		return nil, fmt.Errorf("Error listing trash: %w", err)
+	}
+
+	if err := s.decorate(ctx, model.TaskPtrs(tasks)...); err != nil {
 		return nil, err
 	}
+	return tasks, nil
+}
 
-	if task == nil {
-		return nil, errors.New("task not found")
+// ------------------------RESTORE TASK--------------------------------
+func (s *TaskService) RestoreTask(ctx context.Context, id string) (*model.Task, error) {
+
+	task, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		task, err := tx.repo.FindDeletedByIDForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		// Só dá para restaurar o que está na lixeira
+		if task == nil {
+			return nil, notFound("task not found in trash")
+		}
+
+		if err := tx.repo.Restore(ctx, id); err != nil {
+			return nil, versionError(err, 0)
+		}
+		return task, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// MELHORIA: Validar title se fornecido
-	if title != "" {
-		if len(title) > 255 {
-			return nil, errors.New("title is too long (max 255)")
+	task.DeletedAt = nil
+	task.Version++
+	if err := s.decorate(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ------------------------PURGE TASK--------------------------------
+// Remove definitivamente, tanto tasks ativas quanto tasks na lixeira;
+// version funciona como no PatchTask
+func (s *TaskService) PurgeTask(ctx context.Context, id string, version int64) error {
+
+	_, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		task, err := tx.repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if task == nil {
+			task, err = tx.repo.FindDeletedByIDForUpdate(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if task == nil {
+			return nil, notFound("task not found")
 		}
-		task.Title = title
+		if err := checkVersion(task, version); err != nil {
+			return nil, err
+		}
+
+		return nil, tx.repo.Purge(ctx, id)
+	})
+	return err
+}
+
+// ------------------------UPDATE TASK--------------------------------
+// UpdateTaskInput é a task inteira (corpo do PUT /tasks/{id}): o PUT substitui tudo,
+// e campo ausente vira vazio (sem descrição, sem prazo, sem tags, task raiz).
+// É validado como um patch com todos os campos presentes.
+type UpdateTaskInput struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	DueAt       *time.Time `json:"due_at"`
+	Tags        []string   `json:"tags"`
+	ParentID    string     `json:"parent_id"` // vazio = task raiz
+}
+
+// patch converte a substituição em um patch que informa todos os campos
+func (in UpdateTaskInput) patch() PatchTaskInput {
+	p := PatchTaskInput{
+		Title:       Some(in.Title),
+		Description: Some(in.Description),
+		Status:      Some(in.Status),
+		Priority:    Some(in.Priority),
+		DueAt:       Null[time.Time](),
+		Tags:        Null[[]string](),
+		ParentID:    Null[string](),
+	}
+	if in.DueAt != nil {
+		p.DueAt = Some(*in.DueAt)
+	}
+	if in.Tags != nil {
+		p.Tags = Some(in.Tags)
 	}
+	if in.ParentID != "" {
+		p.ParentID = Some(in.ParentID)
+	}
+	return p
+}
 
-	if description != "" {
-		task.Description = description
+// UpdateTask substitui a task (PUT); version funciona como no PatchTask
+func (s *TaskService) UpdateTask(ctx context.Context, id string, in UpdateTaskInput, version int64) (*model.Task, error) {
+	return s.PatchTask(ctx, id, in.patch(), version)
+}
+
+// ------------------------UPSERT TASK--------------------------------
+// UpsertTask é o PUT /tasks/{id}: substitui a task ou, se o id ainda não existe, cria
+// a task com esse id (um UUID em minúsculas). created diz qual dos dois aconteceu.
+// Com version (If-Match, inclusive AnyVersion) a task precisa existir.
+func (s *TaskService) UpsertTask(ctx context.Context, id string, in UpdateTaskInput, version int64) (task *model.Task, created bool, err error) {
+	task, err = s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		existing, err := tx.repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing == nil {
+			if version != 0 {
+				return nil, preconditionFailed("task does not exist")
+			}
+			task, err := tx.createWithID(ctx, id, in)
+			if !errors.Is(err, errTaskExists) {
+				created = err == nil
+				return task, err
+			}
+			// Outra requisição criou a task entre a busca e o INSERT: segue como substituição
+		}
+		return tx.patchTask(ctx, id, in.patch(), version)
+	})
+	if err != nil {
+		return nil, false, err
 	}
 
-	// CORREÇÃO: Validar status enum se fornecido
-	// Antes aceitava qualquer valor, agora valida contra as constantes do model
-	if status != "" {
-		if status != model.StatusPending && status != model.StatusCompleted {
-			return nil, errors.New("invalid status: must be 'pending' or 'completed'")
+	// A task criada já vem com available_transitions; a substituída é decorada aqui
+	if !created {
+		if err := s.decorate(ctx, task); err != nil {
+			return nil, false, err
 		}
-		task.Status = status
+	}
+	return task, created, nil
+}
+
+// errTaskExists avisa que o id já estava em uso quando createWithID tentou gravar
+var errTaskExists = errors.New("task already exists")
+
+// createWithID cria a task do PUT com o id escolhido pelo cliente; roda dentro do inTx do UpsertTask
+func (s *TaskService) createWithID(ctx context.Context, id string, in UpdateTaskInput) (*model.Task, error) {
+	var errs validationErrors
+	if !isUUID(id) {
+		errs.add("id", "id must be a lowercase UUID (e.g. 3f2b8c1e-7a4d-4e5f-9b6a-1c2d3e4f5a6b)")
+	}
+	errs.checkStruct(in.patch())
+	errs.addErr(validateDueAt(in.DueAt, s.dueAtNow()))
+
+	tags, err := normalizeTags(in.Tags)
+	errs.addErr(err)
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+
+	// Id na lixeira não é reaproveitado. Lido com lock, na transação do UpsertTask: um restore
+	// ou purge ao mesmo tempo espera o commit
+	deleted, err := s.repo.FindDeletedByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted != nil {
+		return nil, conflict("task is in the trash: restore it first")
 	}
 
-	// CORREÇÃO: Validar priority enum se fornecido
-	// Antes aceitava qualquer valor, agora valida contra as constantes do model
-	if priority != "" {
-		if priority != model.PriorityLow && priority != model.PriorityMedium && priority != model.PriorityHigh {
-			return nil, errors.New("invalid priority: must be 'low', 'medium' or 'high'")
+	var parentID *string
+	if in.ParentID != "" {
+		if err := s.validateParent(ctx, nil, in.ParentID); err != nil {
+			return nil, err
 		}
-		task.Priority = priority
+		parentID = &in.ParentID
 	}
 
-	task.UpdatedAt = time.Now()
+	now := time.Now()
+	task := &model.Task{
+		ID:          id,
+		ParentID:    parentID,
+		Title:       in.Title,
+		Description: in.Description,
+		Priority:    in.Priority,
+		DueAt:       in.DueAt,
+		Tags:        tags,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	// A task já pode nascer em qualquer status (ex.: sincronizada de outro sistema)
+	setStatus(task, in.Status, now, nil)
+
+	created, err := s.repo.SaveIfAbsent(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, errTaskExists
+	}
+
+	task.Transitions = availableTransitions(task.Status)
+	return task, nil
+}
+
+// isUUID aceita só o formato canônico, o mesmo dos ids gerados pelo CreateTask
+func isUUID(id string) bool {
+	u, err := uuid.Parse(id)
+	return err == nil && u.String() == id
+}
+
+// ------------------------PATCH TASK--------------------------------
+// PatchTaskInput é um JSON Merge Patch (RFC 7396) da task: campo ausente mantém
+// o valor atual e null limpa o campo (descrição vazia, sem prazo, sem tags, task raiz).
+// title, status e priority não aceitam null.
+type PatchTaskInput struct {
+	Title       Optional[string]    `json:"title" validate:"required,max=255"`
+	Description Optional[string]    `json:"description" validate:"max=10000"`
+	Status      Optional[string]    `json:"status" validate:"required,enum=status"`
+	Priority    Optional[string]    `json:"priority" validate:"required,enum=priority"`
+	DueAt       Optional[time.Time] `json:"due_at"`
+	Tags        Optional[[]string]  `json:"tags"`
+	ParentID    Optional[string]    `json:"parent_id" validate:"max=36"`
+}
 
-	err = s.repo.Update(ctx, task)
+// PatchTask aplica só os campos presentes no patch. version é a versão que o cliente
+// leu (If-Match): se não for a atual, nada muda e o erro é ErrPrecondition; 0 = qualquer versão.
+func (s *TaskService) PatchTask(ctx context.Context, id string, in PatchTaskInput, version int64) (*model.Task, error) {
+	task, err := s.inTx(ctx, func(tx *TaskService) (*model.Task, error) {
+		return tx.patchTask(ctx, id, in, version)
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.decorate(ctx, task); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// patchTask aplica o patch dentro da transação do PatchTask (ou do UpsertTask)
+func (s *TaskService) patchTask(ctx context.Context, id string, in PatchTaskInput, version int64) (*model.Task, error) {
+	task, err := s.lockTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task, version); err != nil {
+		return nil, err
+	}
+
+	var errs validationErrors
+	errs.checkStruct(in)
+	// Reenviar o prazo atual (mesmo que já vencido) não é erro: o PUT manda a task inteira
+	if in.DueAt.Valued() && (task.DueAt == nil || !task.DueAt.Equal(in.DueAt.Value)) {
+		errs.addErr(validateDueAt(&in.DueAt.Value, s.dueAtNow()))
+	}
+	var tags []string
+	if in.Tags.Valued() {
+		tags, err = normalizeTags(in.Tags.Value)
+		errs.addErr(err)
+	}
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+
+	if in.Title.Present {
+		task.Title = in.Title.Value
+	}
+	if in.Description.Present {
+		task.Description = in.Description.Value // null vira ""
+	}
+
+	// A mudança de status precisa ser uma transição permitida (ver lifecycle.go)
+	if in.Status.Present && in.Status.Value != task.Status {
+		if !canTransition(task.Status, in.Status.Value) {
+			return nil, conflict("cannot change status from %q to %q", task.Status, in.Status.Value)
+		}
+		if err := s.checkStatusChange(ctx, task, in.Status.Value); err != nil {
+			return nil, err
+		}
+		setStatus(task, in.Status.Value, time.Now(), nil)
+	}
+
+	if in.Priority.Present {
+		task.Priority = in.Priority.Value
+	}
+	if in.DueAt.Present {
+		task.DueAt = nil
+		if !in.DueAt.Null {
+			task.DueAt = &in.DueAt.Value
+		}
+	}
+	if in.Tags.Present {
+		task.Tags = []string{}
+		if tags != nil {
+			task.Tags = tags
+		}
+	}
+
+	// parent_id null (ou "") transforma em task raiz
+	if in.ParentID.Present {
+		if in.ParentID.Null || in.ParentID.Value == "" {
+			task.ParentID = nil
+		} else {
+			if err := s.validateParent(ctx, task, in.ParentID.Value); err != nil {
+				return nil, err
+			}
+			task.ParentID = &in.ParentID.Value
+		}
+	}
+
+	task.UpdatedAt = time.Now()
+
+	// A versão lida vai no WHERE do UPDATE: se outra escrita passou na frente, nada é gravado
+	if err := s.repo.Update(ctx, task); err != nil {
+		return nil, versionError(err, version)
+	}
 	return task, nil
 }
 
 // ------------------------LIST TASK--------------------------------
-func (s *TaskService) ListTask(ctx context.Context, status string) ([]model.Task, error) {
+// ListQuery reúne os parâmetros da listagem
+type ListQuery struct {
+	Filter model.TaskFilter
+	Sort   string // ex.: "-priority,created_at"; vazio = created_at
+	Limit  int    // 0 = tamanho padrão
+	Cursor string // vazio = primeira página
+}
+
+// ListTask devolve uma página de tasks
+func (s *TaskService) ListTask(ctx context.Context, q ListQuery) (*model.TaskPage, error) {
+
+	if err := validateFilter(&q.Filter); err != nil {
+		return nil, err
+	}
 
-	tasks, err := s.repo.FindAll(ctx, status)
+	sortFields, sortKey, err := parseSort(q.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := repository.ListOptions{Sort: sortFields, Limit: s.pageSize(q.Limit)}
+
+	if q.Cursor != "" {
+		after, err := decodeCursor(q.Cursor, sortKey, sortFields)
+		if err != nil {
+			return nil, err
+		}
+		opts.After = after
+	}
+
+	// Pede um item a mais só para saber se existe próxima página
+	size := opts.Limit
+	opts.Limit++
+
+	tasks, err := s.repo.FindAll(ctx, q.Filter, opts)
 	if err != nil {
 		return nil, fmt.Errorf("Error listing tasks: %w", err)
 	}
-	return tasks, nil
+
+	page := &model.TaskPage{Items: tasks}
+	if len(tasks) > size {
+		page.Items = tasks[:size]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(sortKey, repository.NewCursor(page.Items[size-1], sortFields))
+	}
+
+	if err := s.decorate(ctx, model.TaskPtrs(page.Items)...); err != nil {
+		return nil, err
+	}
+
+	// Sempre "items": [] no JSON, nunca null
+	if page.Items == nil {
+		page.Items = []model.Task{}
+	}
+
+	return page, nil
+}
+
+// ------------------------UPCOMING TASKS--------------------------------
+// Janela padrão e máxima da visão de próximas tasks
+const (
+	DefaultUpcomingWindow = 72 * time.Hour
+	MaxUpcomingWindow     = 30 * 24 * time.Hour
+)
+
+// UpcomingQuery reúne os parâmetros da visão de próximas tasks
+type UpcomingQuery struct {
+	Within time.Duration // 0 = DefaultUpcomingWindow
+	Limit  int
+	Cursor string
+}
+
+// UpcomingTasks lista as tasks em aberto que vencem entre agora e agora + Within,
+// do prazo mais próximo para o mais distante
+func (s *TaskService) UpcomingTasks(ctx context.Context, q UpcomingQuery) (*model.TaskPage, error) {
+	within := q.Within
+	if within == 0 {
+		within = DefaultUpcomingWindow
+	}
+	if within < 0 || within > MaxUpcomingWindow {
+		return nil, invalid("within", "within must be between 0 and %s", MaxUpcomingWindow)
+	}
+
+	now := s.now()
+	until := now.Add(within)
+
+	return s.ListTask(ctx, ListQuery{
+		Filter: model.TaskFilter{
+			Statuses:  openStatuses(),
+			DueAfter:  &now,
+			DueBefore: &until,
+			Now:       now,
+		},
+		Sort:   "due_at",
+		Limit:  q.Limit,
+		Cursor: q.Cursor,
+	})
+}
+
+// openStatuses são os status válidos que não encerram a task
+func openStatuses() []string {
+	var open []string
+	for _, status := range model.Statuses {
+		if !model.IsClosed(status) {
+			open = append(open, status)
+		}
+	}
+	return open
+}
+
+// ------------------------LIST SUBTASKS--------------------------------
+// ListSubtasks lista as subtasks diretas da task, com os mesmos filtros e paginação da listagem
+func (s *TaskService) ListSubtasks(ctx context.Context, id string, q ListQuery) (*model.TaskPage, error) {
+	if _, err := s.findTask(ctx, id); err != nil {
+		return nil, err
+	}
+
+	q.Filter.ParentID = id
+	return s.ListTask(ctx, q)
+}
+
+// ------------------------LIST TAGS--------------------------------
+// ListTags devolve as tags em uso nas tasks ativas, com a contagem de cada uma
+func (s *TaskService) ListTags(ctx context.Context) ([]model.TagCount, error) {
+	counts, err := s.repo.TagCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing tags: %w", err)
+	}
+	if counts == nil {
+		counts = []model.TagCount{}
+	}
+	return counts, nil
+}
+
+// ------------------------SEARCH TASK--------------------------------
+// maxSearchLength limita o texto da busca
+const maxSearchLength = 200
+
+// SearchQuery reúne os parâmetros da busca textual
+type SearchQuery struct {
+	Text   string
+	Filter model.TaskFilter
+	Limit  int    // 0 = tamanho padrão
+	Cursor string // vazio = primeira página
+}
+
+// SearchTasks busca por relevância em título e descrição, no mesmo envelope da listagem
+func (s *TaskService) SearchTasks(ctx context.Context, q SearchQuery) (*model.SearchPage, error) {
+
+	text := strings.TrimSpace(q.Text)
+	if text == "" {
+		return nil, invalid("q", "search query is required")
+	}
+	if len(text) > maxSearchLength {
+		return nil, invalid("q", "search query is too long (max %d)", maxSearchLength)
+	}
+
+	terms := repository.SearchTerms(text)
+	if len(terms) == 0 {
+		return nil, invalid("q", "search query must contain letters or digits")
+	}
+
+	if err := validateFilter(&q.Filter); err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if q.Cursor != "" {
+		var err error
+		if offset, err = decodeSearchCursor(q.Cursor, text); err != nil {
+			return nil, err
+		}
+	}
+
+	// Um item a mais para saber se existe próxima página
+	size := s.pageSize(q.Limit)
+	results, err := s.repo.Search(ctx, text, q.Filter, repository.SearchOptions{Limit: size + 1, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("Error searching tasks: %w", err)
+	}
+
+	page := &model.SearchPage{Items: results}
+	if len(results) > size {
+		page.Items = results[:size]
+		page.HasMore = true
+		page.NextCursor = encodeSearchCursor(text, offset+size)
+	}
+
+	ptrs := make([]*model.Task, len(page.Items))
+	for i := range page.Items {
+		page.Items[i].Highlight = highlight(page.Items[i].Task, terms)
+		ptrs[i] = &page.Items[i].Task
+	}
+	if err := s.decorate(ctx, ptrs...); err != nil {
+		return nil, err
+	}
+
+	if page.Items == nil {
+		page.Items = []model.SearchResult{}
+	}
+
+	return page, nil
+}
+
+// validateFilter rejeita valores de enum desconhecidos e intervalos de data invertidos,
+// e normaliza as tags do filtro com as mesmas regras das tasks
+func validateFilter(f *model.TaskFilter) error {
+	var errs validationErrors
+	errs.checkEnum("status", "status", f.Statuses...)
+	errs.checkEnum("priority", "priority", f.Priorities...)
+	errs.checkEnum("tag_mode", "tag_mode", f.TagMode)
+
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		errs.add("created_after", "created_after must be before created_before")
+	}
+	if f.UpdatedAfter != nil && f.UpdatedBefore != nil && !f.UpdatedAfter.Before(*f.UpdatedBefore) {
+		errs.add("updated_after", "updated_after must be before updated_before")
+	}
+	if f.DueAfter != nil && f.DueBefore != nil && !f.DueAfter.Before(*f.DueBefore) {
+		errs.add("due_after", "due_after must be before due_before")
+	}
+
+	tags, err := normalizeTags(f.Tags)
+	if err != nil {
+		errs.addErr(renameField(err, "tag"))
+	}
+	if err := errs.err(); err != nil {
+		return err
+	}
+	f.Tags = tags
+
+	return nil
+}
+
+// maxDueYear é o último ano aceito em due_at
+const maxDueYear = 9999
+
+// validateDueAt aceita prazo vazio; prazo informado não pode estar no passado
+// (now zero = sem essa regra, ver dueAtNow)
+func validateDueAt(dueAt *time.Time, now time.Time) error {
+	if dueAt == nil {
+		return nil
+	}
+	if !now.IsZero() && dueAt.Before(now) {
+		return invalid("due_at", "due_at must not be in the past")
+	}
+	if dueAt.UTC().Year() > maxDueYear {
+		return invalid("due_at", "due_at must be before year %d", maxDueYear+1)
+	}
+	return nil
 }
@@ -2,71 +2,32 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
 )
 
 // CORREÇÃO: Testes movidos para arquivo separado (_test.go)
 // Isso é a convenção padrão do Go para testes unitários.
 
-// Mock Repository - Implementação fake para testes
-// Simula o comportamento do repository sem acessar banco de dados real
-type mockRepository struct {
-	tasks map[string]*model.Task
+// Os testes usam o repository em memória no lugar do MySQL.
+// newTestRepo devolve um repository vazio e setupTask insere dados de teste nele.
+func newTestRepo() *repository.MemoryTaskRepository {
+	return repository.NewMemoryTaskRepository()
 }
 
-// Save simula salvar uma task no banco
-func (m *mockRepository) Save(ctx context.Context, task *model.Task) error {
-	if m.tasks == nil {
-		m.tasks = make(map[string]*model.Task)
+func setupTask(t *testing.T, repo *repository.MemoryTaskRepository, task *model.Task) {
+	t.Helper()
+	if err := repo.Save(context.Background(), task); err != nil {
+		t.Fatalf("setup failed: %v", err)
 	}
-	m.tasks[task.ID] = task
-	return nil
-}
-
-// FindByID simula buscar uma task por ID
-func (m *mockRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
-	task, ok := m.tasks[id]
-	if !ok {
-		return nil, nil
-	}
-	return task, nil
-}
-
-// FindAll simula listar todas as tasks
-func (m *mockRepository) FindAll(ctx context.Context, status string) ([]model.Task, error) {
-	var result []model.Task
-	for _, task := range m.tasks {
-		if status == "" || task.Status == status {
-			result = append(result, *task)
-		}
-	}
-	return result, nil
-}
-
-// Update simula atualizar uma task
-func (m *mockRepository) Update(ctx context.Context, task *model.Task) error {
-	if m.tasks == nil {
-		m.tasks = make(map[string]*model.Task)
-	}
-	m.tasks[task.ID] = task
-	return nil
-}
-
-// Delete simula deletar uma task
-func (m *mockRepository) Delete(ctx context.Context, id string) error {
-	delete(m.tasks, id)
-	return nil
-}
-
-// SetupTask é um helper para configurar o mock com dados de teste
-func (m *mockRepository) SetupTask(task *model.Task) {
-	if m.tasks == nil {
-		m.tasks = make(map[string]*model.Task)
-	}
-	m.tasks[task.ID] = task
 }
 
 // ------------------------ TESTES ------------------------
@@ -108,14 +69,14 @@ func TestCreateTask(t *testing.T) {
 		},
 	}
 
-	// Cria mock repository e service
-	mockRepo := &mockRepository{}
-	service := &TaskService{repo: mockRepo}
+	// Cria repository em memória e service
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
 
 	// Executa cada caso de teste
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			task, err := service.CreateTask(context.Background(), tt.title, tt.description)
+			task, err := service.CreateTask(context.Background(), CreateTaskInput{Title: tt.title, Description: tt.description})
 
 			// Verifica se erro ocorreu quando esperado
 			if tt.wantErr && err == nil {
@@ -142,11 +103,11 @@ func TestCreateTask(t *testing.T) {
 }
 
 func TestCompleteTask(t *testing.T) {
-	mockRepo := &mockRepository{}
-	service := &TaskService{repo: mockRepo}
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
 
 	// Arrange: Configura uma task pending
-	mockRepo.SetupTask(&model.Task{
+	setupTask(t, repo, &model.Task{
 		ID:        "1",
 		Title:     "Test Task",
 		Status:    model.StatusPending,
@@ -155,7 +116,7 @@ func TestCompleteTask(t *testing.T) {
 	})
 
 	// Act: Marca como concluída
-	task, err := service.CompleteTask(context.Background(), "1")
+	task, err := service.CompleteTask(context.Background(), "1", false, 0)
 
 	// Assert: Verifica resultado
 	if err != nil {
@@ -167,11 +128,11 @@ func TestCompleteTask(t *testing.T) {
 }
 
 func TestCompleteTask_AlreadyCompleted(t *testing.T) {
-	mockRepo := &mockRepository{}
-	service := &TaskService{repo: mockRepo}
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
 
 	// Arrange: Task já está completed
-	mockRepo.SetupTask(&model.Task{
+	setupTask(t, repo, &model.Task{
 		ID:        "1",
 		Title:     "Test Task",
 		Status:    model.StatusCompleted,
@@ -180,7 +141,7 @@ func TestCompleteTask_AlreadyCompleted(t *testing.T) {
 	})
 
 	// Act: Tenta marcar como concluída novamente
-	task, err := service.CompleteTask(context.Background(), "1")
+	task, err := service.CompleteTask(context.Background(), "1", false, 0)
 
 	// Assert: Deve retornar erro
 	if err == nil {
@@ -192,11 +153,11 @@ func TestCompleteTask_AlreadyCompleted(t *testing.T) {
 }
 
 func TestUpdateTask_ValidateEnums(t *testing.T) {
-	mockRepo := &mockRepository{}
-	service := &TaskService{repo: mockRepo}
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
 
 	// Setup: Cria uma task
-	mockRepo.SetupTask(&model.Task{
+	setupTask(t, repo, &model.Task{
 		ID:        "1",
 		Title:     "Original",
 		Status:    model.StatusPending,
@@ -233,8 +194,237 @@ func TestUpdateTask_ValidateEnums(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := service.UpdateTask(context.Background(), "1", "Title", "Desc", tt.status, tt.priority)
+			_, err := service.UpdateTask(context.Background(), "1", UpdateTaskInput{Title: "Title", Description: "Desc", Status: tt.status, Priority: tt.priority}, 0)
+
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDeleteTask_SoftDelete(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+
+	setupTask(t, repo, &model.Task{ID: "1", Title: "Test Task", Status: model.StatusPending})
+
+	if err := service.DeleteTask(context.Background(), "1", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Some das buscas normais...
+	if _, err := service.GetTask(context.Background(), "1"); err == nil {
+		t.Error("expected deleted task to be hidden from GetTask")
+	}
+
+	// ...mas continua na lixeira
+	trash, err := service.ListTrash(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trash) != 1 || trash[0].ID != "1" {
+		t.Errorf("expected task 1 in trash, got %v", trash)
+	}
+}
+
+func TestDeleteTask_OpenSubtasksAndDependents(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+	ctx := context.Background()
+
+	parent := "parent"
+	setupTask(t, repo, &model.Task{ID: parent, Title: "Parent", Status: model.StatusPending})
+	setupTask(t, repo, &model.Task{ID: "child", Title: "Child", Status: model.StatusPending, ParentID: &parent})
+	setupTask(t, repo, &model.Task{ID: "blocker", Title: "Blocker", Status: model.StatusPending})
+	setupTask(t, repo, &model.Task{ID: "blocked", Title: "Blocked", Status: model.StatusPending})
+	if err := repo.AddDependency(ctx, "blocked", "blocker"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Subtask aberta e task bloqueada aberta impedem a lixeira
+	for _, id := range []string{parent, "blocker"} {
+		if err := service.DeleteTask(ctx, id, 0); !errors.Is(err, ErrConflict) {
+			t.Errorf("%s: expected ErrConflict, got %v", id, err)
+		}
+		if _, err := service.GetTask(ctx, id); err != nil {
+			t.Errorf("%s: expected task still active, got %v", id, err)
+		}
+	}
+
+	// Encerradas, não impedem mais
+	for _, id := range []string{"child", "blocked"} {
+		if _, err := service.TransitionTask(ctx, id, ActionCancel, 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	for _, id := range []string{parent, "blocker"} {
+		if err := service.DeleteTask(ctx, id, 0); err != nil {
+			t.Errorf("%s: unexpected error: %v", id, err)
+		}
+	}
+}
+
+func TestRestoreTask(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+
+	setupTask(t, repo, &model.Task{ID: "1", Title: "Test Task", Status: model.StatusPending})
+
+	// Task ativa não pode ser restaurada
+	if _, err := service.RestoreTask(context.Background(), "1"); err == nil {
+		t.Error("expected error restoring a task that is not in trash")
+	}
+
+	if err := service.DeleteTask(context.Background(), "1", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	task, err := service.RestoreTask(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.DeletedAt != nil {
+		t.Errorf("expected nil deleted_at, got %v", task.DeletedAt)
+	}
+	if _, err := service.GetTask(context.Background(), "1"); err != nil {
+		t.Errorf("expected restored task to be visible, got %v", err)
+	}
+}
+
+func TestPurgeTask(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+
+	setupTask(t, repo, &model.Task{ID: "1", Title: "Active", Status: model.StatusPending})
+	setupTask(t, repo, &model.Task{ID: "2", Title: "Trashed", Status: model.StatusPending})
+	if err := service.DeleteTask(context.Background(), "2", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
+	// Purge funciona tanto para tasks ativas quanto para a lixeira
+	for _, id := range []string{"1", "2"} {
+		if err := service.PurgeTask(context.Background(), id, 0); err != nil {
+			t.Errorf("unexpected error purging %s: %v", id, err)
+		}
+	}
+
+	active, _ := service.ListTask(context.Background(), ListQuery{})
+	trash, _ := service.ListTrash(context.Background())
+	if len(active.Items)+len(trash) != 0 {
+		t.Errorf("expected no tasks left, got %d active and %d in trash", len(active.Items), len(trash))
+	}
+
+	if err := service.PurgeTask(context.Background(), "1", 0); err == nil {
+		t.Error("expected error purging a missing task")
+	}
+}
+
+func TestListTask_Pagination(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo, cfg: Config{DefaultPageSize: 2, MaxPageSize: 3}}
+
+	// 5 tasks, duas com o mesmo created_at para exercitar o desempate por id
+	base := time.Now()
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		createdAt := base.Add(time.Duration(i) * time.Second)
+		if id == "c" {
+			createdAt = base.Add(time.Second) // mesmo instante de "b"
+		}
+		setupTask(t, repo, &model.Task{ID: id, Title: id, Status: model.StatusPending, CreatedAt: createdAt})
+	}
+
+	// Percorre tudo com o tamanho padrão (2)
+	var (
+		seen   []string
+		cursor string
+		pages  int
+	)
+	for {
+		page, err := service.ListTask(context.Background(), ListQuery{Cursor: cursor})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		pages++
+		for _, task := range page.Items {
+			seen = append(seen, task.ID)
+		}
+		if !page.HasMore {
+			if page.NextCursor != "" {
+				t.Errorf("expected empty next_cursor on last page, got %q", page.NextCursor)
+			}
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	want := []string{"a", "b", "c", "d", "e"}
+	if len(seen) != len(want) {
+		t.Fatalf("expected %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, seen)
+		}
+	}
+	if pages != 3 {
+		t.Errorf("expected 3 pages, got %d", pages)
+	}
+
+	// limit acima do máximo é reduzido ao máximo (3)
+	page, err := service.ListTask(context.Background(), ListQuery{Limit: 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Items) != 3 || !page.HasMore {
+		t.Errorf("expected 3 items and has_more, got %d items, has_more=%v", len(page.Items), page.HasMore)
+	}
+}
+
+func TestListTask_InvalidCursor(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+
+	if _, err := service.ListTask(context.Background(), ListQuery{Cursor: "not-a-cursor!"}); err == nil {
+		t.Error("expected error for invalid cursor, got nil")
+	}
+}
+
+func TestListTask_EmptyIsNotNull(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+
+	page, err := service.ListTask(context.Background(), ListQuery{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if page.Items == nil || page.HasMore {
+		t.Errorf("expected empty non-nil items and has_more=false, got %#v", page)
+	}
+}
+
+func TestListTask_ValidateFilter(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  model.TaskFilter
+		wantErr bool
+	}{
+		{name: "empty filter", filter: model.TaskFilter{}},
+		{name: "valid enums", filter: model.TaskFilter{Statuses: []string{model.StatusPending}, Priorities: []string{model.PriorityHigh, model.PriorityLow}}},
+		{name: "invalid status", filter: model.TaskFilter{Statuses: []string{"done"}}, wantErr: true},
+		{name: "invalid priority", filter: model.TaskFilter{Priorities: []string{model.PriorityHigh, "urgent"}}, wantErr: true},
+		{name: "valid range", filter: model.TaskFilter{CreatedAfter: &now, CreatedBefore: &later}},
+		{name: "inverted range", filter: model.TaskFilter{UpdatedAfter: &later, UpdatedBefore: &now}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ListTask(context.Background(), ListQuery{Filter: tt.filter})
 			if tt.wantErr && err == nil {
 				t.Error("expected error, got nil")
 			}
@@ -244,3 +434,1356 @@ func TestUpdateTask_ValidateEnums(t *testing.T) {
 		})
 	}
 }
+
+func TestListTask_Sort(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo, cfg: Config{DefaultPageSize: 2}}
+
+	base := time.Now()
+	tasks := []model.Task{
+		{ID: "1", Title: "banana", Priority: model.PriorityLow},
+		{ID: "2", Title: "Apple", Priority: model.PriorityHigh},
+		{ID: "3", Title: "cherry", Priority: model.PriorityMedium},
+		{ID: "4", Title: "date", Priority: model.PriorityHigh},
+	}
+	for i := range tasks {
+		tasks[i].Status = model.StatusPending
+		tasks[i].CreatedAt = base.Add(time.Duration(i) * time.Second)
+		setupTask(t, repo, &tasks[i])
+	}
+
+	tests := []struct {
+		name    string
+		sort    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "default is created_at", sort: "", want: []string{"1", "2", "3", "4"}},
+		{name: "priority is semantic, not alphabetical", sort: "-priority", want: []string{"2", "4", "3", "1"}},
+		{name: "priority then newest", sort: "-priority,-created_at", want: []string{"4", "2", "3", "1"}},
+		{name: "title ignores case", sort: "title", want: []string{"2", "1", "3", "4"}},
+		{name: "unknown field", sort: "id; DROP TABLE tasks", wantErr: true},
+		{name: "duplicated field", sort: "title,-title", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Percorre todas as páginas para validar o cursor com a ordenação
+			var (
+				got    []string
+				cursor string
+			)
+			for {
+				page, err := service.ListTask(context.Background(), ListQuery{Sort: tt.sort, Cursor: cursor})
+				if tt.wantErr {
+					if err == nil {
+						t.Fatal("expected error, got nil")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				for _, task := range page.Items {
+					got = append(got, task.ID)
+				}
+				if !page.HasMore {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestListTask_CursorBoundToSort(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo, cfg: Config{DefaultPageSize: 1}}
+
+	for _, id := range []string{"1", "2"} {
+		setupTask(t, repo, &model.Task{ID: id, Title: id, Status: model.StatusPending, Priority: model.PriorityLow})
+	}
+
+	page, err := service.ListTask(context.Background(), ListQuery{Sort: "title"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Cursor de uma ordenação não vale para outra
+	if _, err := service.ListTask(context.Background(), ListQuery{Sort: "-title", Cursor: page.NextCursor}); err == nil {
+		t.Error("expected error reusing cursor with a different sort")
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo, cfg: Config{DefaultPageSize: 2}}
+
+	base := time.Now()
+	tasks := []model.Task{
+		{ID: "1", Title: "Fix login bug", Description: "users can't login"},
+		{ID: "2", Title: "Write docs", Description: "explain the login flow"},
+		{ID: "3", Title: "Refactor", Description: "nothing related"},
+		{ID: "4", Title: "Login <form>", Description: ""},
+	}
+	for i := range tasks {
+		tasks[i].Status = model.StatusPending
+		tasks[i].Priority = model.PriorityMedium
+		tasks[i].CreatedAt = base.Add(time.Duration(i) * time.Second)
+		setupTask(t, repo, &tasks[i])
+	}
+
+	first, err := service.SearchTasks(context.Background(), SearchQuery{Text: "login bug"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Título com os dois termos pontua mais
+	if len(first.Items) != 2 || first.Items[0].ID != "1" || !first.HasMore {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if first.Items[0].Highlight.Title != "Fix <mark>login</mark> <mark>bug</mark>" {
+		t.Errorf("unexpected title highlight: %q", first.Items[0].Highlight.Title)
+	}
+
+	second, err := service.SearchTasks(context.Background(), SearchQuery{Text: "login bug", Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Items) != 1 || second.HasMore {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+
+	// Todos os que casam aparecem uma vez só, e o que não casa fica de fora
+	seen := map[string]bool{}
+	for _, item := range append(first.Items, second.Items...) {
+		seen[item.ID] = true
+	}
+	if len(seen) != 3 || seen["3"] {
+		t.Errorf("unexpected results: %v", seen)
+	}
+
+	// Cursor de outra busca é rejeitado
+	if _, err := service.SearchTasks(context.Background(), SearchQuery{Text: "docs", Cursor: first.NextCursor}); err == nil {
+		t.Error("expected error reusing cursor with a different query")
+	}
+}
+
+func TestSearchTasks_Validation(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+
+	for _, text := range []string{"", "   ", "!!!", strings.Repeat("a", 201)} {
+		if _, err := service.SearchTasks(context.Background(), SearchQuery{Text: text}); err == nil {
+			t.Errorf("expected error for query %q", text)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+	}{
+		{name: "case insensitive", text: "Login and LOGIN", terms: []string{"login"}, want: "<mark>Login</mark> and <mark>LOGIN</mark>"},
+		{name: "escapes html", text: "<b>bug</b>", terms: []string{"bug"}, want: "&lt;b&gt;<mark>bug</mark>&lt;/b&gt;"},
+		{name: "adjacent terms merge", text: "bugfix", terms: []string{"bug", "fix"}, want: "<mark>bugfix</mark>"},
+		{name: "no match", text: "nothing", terms: []string{"bug"}, want: "nothing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markTerms(tt.text, tt.terms); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	long := strings.Repeat("a ", 100) + "needle" + strings.Repeat(" b", 100)
+	got := snippet(long, []string{"needle"})
+	if !strings.Contains(got, "needle") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("expected snippet around the term, got %q", got)
+	}
+}
+
+func TestCreateTask_DueAt(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	service := &TaskService{repo: newTestRepo(), clock: func() time.Time { return now }}
+
+	past := now.Add(-time.Minute)
+	future := now.Add(48 * time.Hour)
+	tooFar := time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		dueAt   *time.Time
+		wantErr bool
+	}{
+		{name: "no due date", dueAt: nil},
+		{name: "future due date", dueAt: &future},
+		{name: "past due date", dueAt: &past, wantErr: true},
+		{name: "year out of range", dueAt: &tooFar, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := service.CreateTask(context.Background(), CreateTaskInput{Title: "Task", DueAt: tt.dueAt})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (task.DueAt == nil) != (tt.dueAt == nil) {
+				t.Errorf("expected due_at %v, got %v", tt.dueAt, task.DueAt)
+			}
+		})
+	}
+}
+
+func TestOverdueAndUpcoming(t *testing.T) {
+	repo := newTestRepo()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	service := &TaskService{repo: repo, clock: func() time.Time { return now }}
+
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	// late: atrasada; doneLate: passou do prazo mas foi concluída; soon e later: dentro e fora da janela
+	tasks := []*model.Task{
+		{ID: "late", Title: "Late", Status: model.StatusPending, DueAt: at(-time.Hour)},
+		{ID: "doneLate", Title: "Done late", Status: model.StatusCompleted, DueAt: at(-time.Hour)},
+		{ID: "soon", Title: "Soon", Status: model.StatusPending, DueAt: at(2 * time.Hour)},
+		{ID: "tomorrow", Title: "Tomorrow", Status: model.StatusPending, DueAt: at(24 * time.Hour)},
+		{ID: "later", Title: "Later", Status: model.StatusPending, DueAt: at(10 * 24 * time.Hour)},
+		{ID: "noDue", Title: "No due date", Status: model.StatusPending},
+	}
+	for _, task := range tasks {
+		task.Priority = model.PriorityMedium
+		task.CreatedAt, task.UpdatedAt = now, now
+		setupTask(t, repo, task)
+	}
+
+	task, err := service.GetTask(context.Background(), "late")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !task.Overdue {
+		t.Error("expected late task to be overdue")
+	}
+
+	overdue := true
+	page, err := service.ListTask(context.Background(), ListQuery{Filter: model.TaskFilter{Overdue: &overdue, Now: now}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != "late" || !page.Items[0].Overdue {
+		t.Errorf("expected only the late task, got %+v", page.Items)
+	}
+
+	upcoming, err := service.UpcomingTasks(context.Background(), UpcomingQuery{Within: 72 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ids []string
+	for _, task := range upcoming.Items {
+		ids = append(ids, task.ID)
+	}
+	if strings.Join(ids, ",") != "soon,tomorrow" {
+		t.Errorf("expected soon,tomorrow, got %v", ids)
+	}
+
+	if _, err := service.UpcomingTasks(context.Background(), UpcomingQuery{Within: 365 * 24 * time.Hour}); err == nil {
+		t.Error("expected error for window above the maximum")
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "nil stays nil", tags: nil, want: nil},
+		{name: "lowercase, trim and sort", tags: []string{" Bug ", "backend"}, want: []string{"backend", "bug"}},
+		{name: "inner spaces become dash", tags: []string{"Customer  X"}, want: []string{"customer-x"}},
+		{name: "duplicates removed", tags: []string{"bug", "BUG", "bug "}, want: []string{"bug"}},
+		{name: "empty tag", tags: []string{"  "}, wantErr: true},
+		{name: "invalid character", tags: []string{"bug,backend"}, wantErr: true},
+		{name: "too long", tags: []string{strings.Repeat("a", 51)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.tags)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || (got == nil) != (tt.want == nil) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTags(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	create := func(title string, tags ...string) *model.Task {
+		task, err := service.CreateTask(ctx, CreateTaskInput{Title: title, Tags: tags})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return task
+	}
+	api := create("API", "Backend", "bug")
+	create("Docs", "docs")
+	create("Login", "backend")
+
+	ids := func(filter model.TaskFilter) string {
+		page, err := service.ListTask(ctx, ListQuery{Filter: filter})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var titles []string
+		for _, task := range page.Items {
+			titles = append(titles, task.Title)
+		}
+		return strings.Join(titles, ",")
+	}
+
+	// O filtro passa pela mesma normalização
+	if got := ids(model.TaskFilter{Tags: []string{"BUG", "docs"}}); got != "API,Docs" {
+		t.Errorf("any: expected API,Docs, got %s", got)
+	}
+	if got := ids(model.TaskFilter{Tags: []string{"backend", "bug"}, TagMode: model.TagMatchAll}); got != "API" {
+		t.Errorf("all: expected API, got %s", got)
+	}
+	if _, err := service.ListTask(ctx, ListQuery{Filter: model.TaskFilter{TagMode: "some"}}); err == nil {
+		t.Error("expected error for invalid tag_mode")
+	}
+
+	counts, err := service.ListTags(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counts) != 3 || counts[0] != (model.TagCount{Tag: "backend", Count: 2}) {
+		t.Errorf("expected backend first with 2 uses, got %+v", counts)
+	}
+
+	// Ausente mantém as tags, null remove
+	updated, err := service.PatchTask(ctx, api.ID, PatchTaskInput{Title: Some("API")}, 0)
+	if err != nil || len(updated.Tags) != 2 {
+		t.Fatalf("expected tags kept, got %v (%v)", updated, err)
+	}
+	updated, err = service.PatchTask(ctx, api.ID, PatchTaskInput{Tags: Null[[]string]()}, 0)
+	if err != nil || len(updated.Tags) != 0 {
+		t.Fatalf("expected tags cleared, got %v (%v)", updated, err)
+	}
+}
+
+func TestSubtasks(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	create := func(title, parentID string) *model.Task {
+		t.Helper()
+		task, err := service.CreateTask(ctx, CreateTaskInput{Title: title, ParentID: parentID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return task
+	}
+
+	root := create("Release", "")
+	build := create("Build", root.ID)
+	docs := create("Docs", root.ID)
+	create("Changelog", docs.ID)
+
+	page, err := service.ListSubtasks(ctx, root.ID, ListQuery{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("expected 2 subtasks, got %d", len(page.Items))
+	}
+
+	if _, err := service.CompleteTask(ctx, build.ID, false, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := service.GetTask(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Progress == nil || *got.Progress != 50 {
+		t.Errorf("expected progress 50, got %v", got.Progress)
+	}
+
+	// Pai com subtasks abertas (inclusive netos) só conclui com cascade
+	if _, err := service.CompleteTask(ctx, root.ID, false, 0); err == nil {
+		t.Error("expected error completing parent with open subtasks")
+	}
+	if _, err := service.PatchTask(ctx, root.ID, PatchTaskInput{Status: Some(model.StatusCompleted)}, 0); err == nil {
+		t.Error("expected error completing parent through update")
+	}
+
+	done, err := service.CompleteTask(ctx, root.ID, true, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if done.Progress == nil || *done.Progress != 100 {
+		t.Errorf("expected progress 100 after cascade, got %v", done.Progress)
+	}
+	open, err := service.ListTask(ctx, ListQuery{Filter: model.TaskFilter{Statuses: []string{model.StatusPending}}})
+	if err != nil || len(open.Items) != 0 {
+		t.Errorf("expected every task completed, got %d open (%v)", len(open.Items), err)
+	}
+}
+
+func TestSubtasks_Validation(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	// Cadeia com maxDepth níveis
+	chain := make([]*model.Task, maxDepth)
+	for i := range chain {
+		parentID := ""
+		if i > 0 {
+			parentID = chain[i-1].ID
+		}
+		task, err := service.CreateTask(ctx, CreateTaskInput{Title: "Level", ParentID: parentID})
+		if err != nil {
+			t.Fatalf("level %d: unexpected error: %v", i+1, err)
+		}
+		chain[i] = task
+	}
+	root, leaf := chain[0], chain[maxDepth-1]
+
+	other, err := service.CreateTask(ctx, CreateTaskInput{Title: "Other"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	withChild, err := service.CreateTask(ctx, CreateTaskInput{Title: "With child"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CreateTask(ctx, CreateTaskInput{Title: "Child", ParentID: withChild.ID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	move := func(id, parentID string) error {
+		_, err := service.PatchTask(ctx, id, PatchTaskInput{ParentID: Some(parentID)}, 0)
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr bool
+	}{
+		{name: "unknown parent", run: func() error {
+			_, err := service.CreateTask(ctx, CreateTaskInput{Title: "x", ParentID: "missing"})
+			return err
+		}, wantErr: true},
+		{name: "too deep on create", run: func() error {
+			_, err := service.CreateTask(ctx, CreateTaskInput{Title: "x", ParentID: leaf.ID})
+			return err
+		}, wantErr: true},
+		{name: "own parent", run: func() error { return move(other.ID, other.ID) }, wantErr: true},
+		{name: "cycle", run: func() error { return move(root.ID, leaf.ID) }, wantErr: true},
+		{name: "subtree too deep", run: func() error { return move(withChild.ID, chain[maxDepth-2].ID) }, wantErr: true},
+		{name: "subtree fits", run: func() error { return move(withChild.ID, chain[maxDepth-3].ID) }},
+		{name: "detach to root", run: func() error { return move(withChild.ID, "") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	create := func(title string) *model.Task {
+		t.Helper()
+		task, err := service.CreateTask(ctx, CreateTaskInput{Title: title})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return task
+	}
+	deploy, build, test := create("Deploy"), create("Build"), create("Test")
+
+	// deploy <- test <- build
+	if _, err := service.AddDependency(ctx, deploy.ID, test.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deps, err := service.AddDependency(ctx, test.ID, build.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(deps.BlockedBy) != 1 || deps.BlockedBy[0].ID != build.ID || len(deps.Blocking) != 1 || deps.Blocking[0].ID != deploy.ID {
+		t.Errorf("unexpected dependencies of test: %+v", deps)
+	}
+
+	tests := []struct {
+		name            string
+		taskID, blocker string
+	}{
+		{name: "self", taskID: build.ID, blocker: build.ID},
+		{name: "duplicate", taskID: deploy.ID, blocker: test.ID},
+		{name: "direct cycle", taskID: test.ID, blocker: deploy.ID},
+		{name: "indirect cycle", taskID: build.ID, blocker: deploy.ID},
+		{name: "unknown blocker", taskID: build.ID, blocker: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.AddDependency(ctx, tt.taskID, tt.blocker); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+
+	// Task com bloqueio pendente não conclui
+	if _, err := service.CompleteTask(ctx, test.ID, false, 0); err == nil {
+		t.Error("expected error completing a blocked task")
+	}
+	if _, err := service.CompleteTask(ctx, build.ID, false, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CompleteTask(ctx, test.ID, false, 0); err != nil {
+		t.Errorf("expected test to complete after build, got %v", err)
+	}
+
+	if err := service.RemoveDependency(ctx, deploy.ID, test.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.RemoveDependency(ctx, deploy.ID, test.ID); err == nil {
+		t.Error("expected error removing a missing dependency")
+	}
+}
+
+func TestWorkPlan(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// release <- (api, docs); api <- schema; done está concluída e não entra no plano
+	tasks := []*model.Task{
+		{ID: "schema", Priority: model.PriorityLow, Status: model.StatusPending},
+		{ID: "api", Priority: model.PriorityHigh, Status: model.StatusPending},
+		{ID: "docs", Priority: model.PriorityMedium, Status: model.StatusPending},
+		{ID: "release", Priority: model.PriorityHigh, Status: model.StatusPending},
+		{ID: "urgent", Priority: model.PriorityHigh, Status: model.StatusPending},
+		{ID: "done", Priority: model.PriorityHigh, Status: model.StatusCompleted},
+	}
+	for i, task := range tasks {
+		task.Title = task.ID
+		task.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		task.UpdatedAt = task.CreatedAt
+		setupTask(t, repo, task)
+	}
+	for _, d := range [][2]string{{"release", "api"}, {"release", "docs"}, {"api", "schema"}, {"docs", "done"}} {
+		if err := repo.AddDependency(ctx, d[0], d[1]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	plan, err := service.WorkPlan(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, item := range plan.Items {
+		got = append(got, item.ID)
+	}
+	// Camada 1 (ready): urgent, docs, schema; camada 2: api; camada 3: release
+	want := "urgent,docs,schema,api,release"
+	if strings.Join(got, ",") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ","))
+	}
+
+	for _, item := range plan.Items {
+		wantReady := item.ID == "urgent" || item.ID == "docs" || item.ID == "schema"
+		if item.Ready != wantReady {
+			t.Errorf("%s: expected ready=%v, got %v (blocked by %v)", item.ID, wantReady, item.Ready, item.BlockedBy)
+		}
+	}
+}
+
+func TestAddDependency_Concurrent(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: slowReadRepo{repo}}
+	ctx := context.Background()
+	setupTask(t, repo, &model.Task{ID: "a", Title: "A", Status: model.StatusPending})
+	setupTask(t, repo, &model.Task{ID: "b", Title: "B", Status: model.StatusPending})
+
+	// a <- b e b <- a ao mesmo tempo: só uma pode passar pela checagem de ciclo
+	errs := make(chan error, 2)
+	var wg sync.WaitGroup
+	for _, pair := range [][2]string{{"a", "b"}, {"b", "a"}} {
+		wg.Add(1)
+		go func(taskID, blockerID string) {
+			defer wg.Done()
+			_, err := service.AddDependency(ctx, taskID, blockerID)
+			errs <- err
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if errors.Is(err, ErrConflict) {
+			failed++
+		} else if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if deps, _ := repo.FindDependencies(ctx); failed != 1 || len(deps) != 1 {
+		t.Errorf("expected one dependency and one conflict, got %v (%d conflicts)", deps, failed)
+	}
+}
+
+func TestAddDependency_ConcurrentLongCycle(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: slowReadRepo{repo}}
+	ctx := context.Background()
+	for _, id := range []string{"a", "b", "c", "d"} {
+		setupTask(t, repo, &model.Task{ID: id, Title: id, Status: model.StatusPending})
+	}
+	for _, d := range [][2]string{{"a", "b"}, {"c", "d"}} {
+		if err := repo.AddDependency(ctx, d[0], d[1]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// b <- c e d <- a travam tasks diferentes, mas juntas fechariam a -> b -> c -> d -> a
+	errs := make(chan error, 2)
+	var wg sync.WaitGroup
+	for _, pair := range [][2]string{{"b", "c"}, {"d", "a"}} {
+		wg.Add(1)
+		go func(taskID, blockerID string) {
+			defer wg.Done()
+			_, err := service.AddDependency(ctx, taskID, blockerID)
+			errs <- err
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	for err := range errs {
+		if errors.Is(err, ErrConflict) {
+			failed++
+		} else if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	deps, _ := repo.FindDependencies(ctx)
+	if failed != 1 || len(deps) != 3 {
+		t.Errorf("expected one new dependency and one conflict, got %v (%d conflicts)", deps, failed)
+	}
+	blockers := blockersByTask(deps)
+	for _, d := range deps {
+		if reaches(blockers, d.BlockerID, d.TaskID) {
+			t.Errorf("expected no cycle, got %v", deps)
+		}
+	}
+}
+
+func TestAvailableTransitions(t *testing.T) {
+	tests := []struct {
+		status string
+		want   []string
+	}{
+		{model.StatusPending, []string{ActionStart, ActionBlock, ActionComplete, ActionCancel}},
+		{model.StatusInProgress, []string{ActionBlock, ActionComplete, ActionCancel}},
+		{model.StatusBlocked, []string{ActionStart, ActionUnblock, ActionCancel}},
+		{model.StatusCompleted, []string{ActionReopen, ActionArchive}},
+		{model.StatusCancelled, []string{ActionReopen, ActionArchive}},
+		// archived é final
+		{model.StatusArchived, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got := availableTransitions(tt.status)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || got == nil {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransitionTask(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+	ctx := context.Background()
+
+	task, err := service.CreateTask(ctx, CreateTaskInput{Title: "Deploy"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Caminho feliz: cada resposta traz as próximas ações possíveis
+	steps := []struct {
+		action  string
+		status  string
+		wantErr bool
+	}{
+		{action: ActionStart, status: model.StatusInProgress},
+		{action: ActionStart, wantErr: true},
+		{action: ActionBlock, status: model.StatusBlocked},
+		{action: ActionComplete, wantErr: true},
+		{action: ActionReopen, wantErr: true},
+		{action: ActionUnblock, status: model.StatusPending},
+		{action: ActionUnblock, wantErr: true},
+		{action: ActionBlock, status: model.StatusBlocked},
+		{action: ActionStart, status: model.StatusInProgress},
+		{action: ActionComplete, status: model.StatusCompleted},
+		{action: ActionReopen, status: model.StatusPending},
+		{action: ActionCancel, status: model.StatusCancelled},
+		{action: ActionArchive, status: model.StatusArchived},
+		{action: ActionReopen, wantErr: true},
+		{action: "finish", wantErr: true},
+	}
+
+	for _, step := range steps {
+		got, err := service.TransitionTask(ctx, task.ID, step.action, 0)
+		if step.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got status %q", step.action, got.Status)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.action, err)
+		}
+		if got.Status != step.status {
+			t.Errorf("%s: expected status %q, got %q", step.action, step.status, got.Status)
+		}
+		if strings.Join(got.Transitions, ",") != strings.Join(availableTransitions(step.status), ",") {
+			t.Errorf("%s: unexpected transitions %v", step.action, got.Transitions)
+		}
+	}
+
+	if _, err := service.TransitionTask(ctx, "missing", ActionStart, 0); err == nil {
+		t.Error("expected error for missing task")
+	}
+}
+
+func TestTransitionTask_Rules(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	parent, _ := service.CreateTask(ctx, CreateTaskInput{Title: "Release"})
+	child, _ := service.CreateTask(ctx, CreateTaskInput{Title: "Build", ParentID: parent.ID})
+
+	// Encerrar exige subtasks encerradas, também pelo PUT
+	if _, err := service.TransitionTask(ctx, parent.ID, ActionCancel, 0); err == nil {
+		t.Error("expected error cancelling parent with open subtasks")
+	}
+	if _, err := service.PatchTask(ctx, parent.ID, PatchTaskInput{Status: Some(model.StatusCancelled)}, 0); err == nil {
+		t.Error("expected error cancelling parent through update")
+	}
+
+	// O cascade não atropela a máquina de estados
+	if _, err := service.TransitionTask(ctx, child.ID, ActionBlock, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CompleteTask(ctx, parent.ID, true, 0); err == nil {
+		t.Error("expected error cascading into a blocked subtask")
+	}
+
+	if _, err := service.TransitionTask(ctx, child.ID, ActionCancel, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CompleteTask(ctx, parent.ID, false, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Subtask não reabre dentro de um pai encerrado
+	if _, err := service.TransitionTask(ctx, child.ID, ActionReopen, 0); err == nil {
+		t.Error("expected error reopening subtask of a closed parent")
+	}
+
+	// PUT só aceita mudanças de status que estão na tabela
+	if _, err := service.PatchTask(ctx, parent.ID, PatchTaskInput{Status: Some(model.StatusInProgress)}, 0); err == nil {
+		t.Error("expected error moving completed task straight to in_progress")
+	}
+	if _, err := service.PatchTask(ctx, parent.ID, PatchTaskInput{Status: Some(model.StatusArchived)}, 0); err != nil {
+		t.Errorf("unexpected error archiving through update: %v", err)
+	}
+}
+
+func TestReopenTask(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	task, _ := service.CreateTask(ctx, CreateTaskInput{Title: "Deploy"})
+	if task.CompletedAt != nil {
+		t.Fatalf("expected no completed_at on new task, got %v", task.CompletedAt)
+	}
+
+	// Task em aberto não é reaberta
+	if _, err := service.ReopenTask(ctx, task.ID, "ana", 0); err == nil {
+		t.Error("expected error reopening a pending task")
+	}
+
+	done, err := service.CompleteTask(ctx, task.ID, false, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if done.CompletedAt == nil {
+		t.Fatal("expected completed_at after completing")
+	}
+
+	reopened, err := service.ReopenTask(ctx, task.ID, "  ana  ", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reopened.Status != model.StatusPending || reopened.CompletedAt != nil {
+		t.Errorf("expected pending without completed_at, got %q %v", reopened.Status, reopened.CompletedAt)
+	}
+	if reopened.ReopenedAt == nil || reopened.ReopenedBy == nil || *reopened.ReopenedBy != "ana" {
+		t.Errorf("expected reopen recorded for ana, got %v %v", reopened.ReopenedAt, reopened.ReopenedBy)
+	}
+
+	// Persistido, não só na resposta
+	got, _ := service.GetTask(ctx, task.ID)
+	if got.CompletedAt != nil || got.ReopenedBy == nil {
+		t.Errorf("expected reopen persisted, got %+v", got)
+	}
+
+	// Sem X-User a reabertura fica sem autor; completed_at volta a ser gravado pelo PUT
+	if _, err := service.PatchTask(ctx, task.ID, PatchTaskInput{Status: Some(model.StatusCompleted)}, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reopened, err = service.ReopenTask(ctx, task.ID, "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reopened.ReopenedBy != nil {
+		t.Errorf("expected anonymous reopen, got %q", *reopened.ReopenedBy)
+	}
+
+	// Bloqueada não é reaberta; unblock volta a pending sem mexer na auditoria da reabertura
+	before := *reopened.ReopenedAt
+	if _, err := service.TransitionTask(ctx, task.ID, ActionBlock, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.ReopenTask(ctx, task.ID, "ana", 0); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict reopening a blocked task, got %v", err)
+	}
+	unblocked, err := service.TransitionTask(ctx, task.ID, ActionUnblock, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if unblocked.Status != model.StatusPending || unblocked.ReopenedAt == nil || !unblocked.ReopenedAt.Equal(before) || unblocked.ReopenedBy != nil {
+		t.Errorf("expected pending with reopen fields untouched, got %q %v %v", unblocked.Status, unblocked.ReopenedAt, unblocked.ReopenedBy)
+	}
+
+	if _, err := service.ReopenTask(ctx, task.ID, strings.Repeat("a", 101), 0); err == nil {
+		t.Error("expected error for too long reopened_by")
+	}
+	if _, err := service.ReopenTask(ctx, "missing", "ana", 0); err == nil {
+		t.Error("expected error for missing task")
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	done, _ := service.CreateTask(ctx, CreateTaskInput{Title: "Done"})
+	if _, err := service.CompleteTask(ctx, done.ID, false, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"get missing task", func() error { _, err := service.GetTask(ctx, "missing"); return err }, ErrNotFound},
+		{"delete missing task", func() error { return service.DeleteTask(ctx, "missing", 0) }, ErrNotFound},
+		{"restore task not in trash", func() error { _, err := service.RestoreTask(ctx, done.ID); return err }, ErrNotFound},
+		{"remove missing dependency", func() error { return service.RemoveDependency(ctx, done.ID, "missing") }, ErrNotFound},
+		{"complete twice", func() error { _, err := service.CompleteTask(ctx, done.ID, false, 0); return err }, ErrConflict},
+		{"start completed task", func() error { _, err := service.TransitionTask(ctx, done.ID, ActionStart, 0); return err }, ErrConflict},
+		{"empty title", func() error { _, err := service.CreateTask(ctx, CreateTaskInput{Title: ""}); return err }, ErrValidation},
+		{"invalid priority", func() error {
+			_, err := service.PatchTask(ctx, done.ID, PatchTaskInput{Priority: Some("urgent")}, 0)
+			return err
+		}, ErrValidation},
+		{"missing blocker", func() error { _, err := service.AddDependency(ctx, done.ID, "missing"); return err }, ErrValidation},
+		{"invalid cursor", func() error { _, err := service.ListTask(ctx, ListQuery{Cursor: "???"}); return err }, ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidation_ReportsAllFields(t *testing.T) {
+	repo := newTestRepo()
+	now := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+	service := &TaskService{repo: repo, clock: func() time.Time { return now }}
+	ctx := context.Background()
+
+	fieldsOf := func(err error) []string {
+		t.Helper()
+		var e *Error
+		if !errors.As(err, &e) || !errors.Is(err, ErrValidation) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		var fields []string
+		for _, f := range e.Fields {
+			fields = append(fields, f.Field)
+		}
+		return fields
+	}
+
+	past := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := service.CreateTask(ctx, CreateTaskInput{
+		Title:       "   ",
+		Description: strings.Repeat("d", 10001),
+		DueAt:       &past,
+		Tags:        []string{"ok", "bad!"},
+	})
+	if got := strings.Join(fieldsOf(err), ","); got != "title,description,due_at,tags" {
+		t.Errorf("expected every invalid field, got %s", got)
+	}
+
+	// Limites contam caracteres, não bytes
+	if _, err := service.CreateTask(ctx, CreateTaskInput{Title: strings.Repeat("ç", 255)}); err != nil {
+		t.Errorf("expected 255 multi-byte characters accepted, got %v", err)
+	}
+
+	setupTask(t, repo, &model.Task{ID: "1", Title: "Task", Status: model.StatusPending, Priority: model.PriorityLow})
+	_, err = service.UpdateTask(ctx, "1", UpdateTaskInput{Status: "done", Priority: "urgent"}, 0)
+	if got := strings.Join(fieldsOf(err), ","); got != "title,status,priority" {
+		t.Errorf("expected title, status and priority, got %s", got)
+	}
+
+	err = validateFilter(&model.TaskFilter{Statuses: []string{"done"}, Priorities: []string{"urgent"}, TagMode: "some"})
+	if got := strings.Join(fieldsOf(err), ","); got != "status,priority,tag_mode" {
+		t.Errorf("expected status, priority and tag_mode, got %s", got)
+	}
+}
+
+func TestOptional_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantPresent bool
+		wantNull    bool
+		wantValue   string
+	}{
+		{name: "absent", body: `{}`},
+		{name: "null", body: `{"description": null}`, wantPresent: true, wantNull: true},
+		{name: "empty string", body: `{"description": ""}`, wantPresent: true},
+		{name: "value", body: `{"description": "text"}`, wantPresent: true, wantValue: "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var in PatchTaskInput
+			if err := json.Unmarshal([]byte(tt.body), &in); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := in.Description
+			if got.Present != tt.wantPresent || got.Null != tt.wantNull || got.Value != tt.wantValue {
+				t.Errorf("expected present=%v null=%v value=%q, got %+v", tt.wantPresent, tt.wantNull, tt.wantValue, got)
+			}
+		})
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+
+	due := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	task, err := service.CreateTask(ctx, CreateTaskInput{Title: "Deploy", Description: "v2", DueAt: &due, Tags: []string{"ops"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Ausente mantém; null limpa
+	patched, err := service.PatchTask(ctx, task.ID, PatchTaskInput{Description: Null[string](), DueAt: Null[time.Time]()}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if patched.Title != "Deploy" || patched.Description != "" || patched.DueAt != nil || len(patched.Tags) != 1 {
+		t.Errorf("expected only description and due_at cleared, got %+v", patched)
+	}
+
+	// title, status e priority não aceitam null
+	_, err = service.PatchTask(ctx, task.ID, PatchTaskInput{Title: Null[string](), Priority: Null[string]()}, 0)
+	if !errors.Is(err, ErrValidation) || !strings.Contains(err.Error(), "title cannot be null") {
+		t.Errorf("expected null title rejected, got %v", err)
+	}
+}
+
+func TestUpdateTask_ReplacesWholeTask(t *testing.T) {
+	repo := newTestRepo()
+	now := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+	service := &TaskService{repo: repo, clock: func() time.Time { return now }}
+	ctx := context.Background()
+
+	past := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	setupTask(t, repo, &model.Task{ID: "1", Title: "Deploy", Description: "v2", Status: model.StatusPending, Priority: model.PriorityLow, DueAt: &past, Tags: []string{"ops"}})
+
+	// Reenviar o prazo atual, mesmo vencido, não é erro
+	updated, err := service.UpdateTask(ctx, "1", UpdateTaskInput{Title: "Deploy", Description: "v2", Status: model.StatusPending, Priority: model.PriorityLow, DueAt: &past}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.DueAt == nil || len(updated.Tags) != 0 {
+		t.Errorf("expected due_at kept and absent tags cleared, got %+v", updated)
+	}
+
+	// Campos ausentes no PUT são limpos
+	updated, err = service.UpdateTask(ctx, "1", UpdateTaskInput{Title: "Deploy", Status: model.StatusPending, Priority: model.PriorityHigh}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Description != "" || updated.DueAt != nil || updated.Priority != model.PriorityHigh {
+		t.Errorf("expected description and due_at cleared, got %+v", updated)
+	}
+}
+
+func TestPatchTask_Version(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+	ctx := context.Background()
+
+	task, err := service.CreateTask(ctx, CreateTaskInput{Title: "Deploy"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// If-Match com a versão atual grava e incrementa a versão
+	updated, err := service.PatchTask(ctx, task.ID, PatchTaskInput{Title: Some("Deploy v2")}, task.Version)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Version != task.Version+1 {
+		t.Errorf("expected version %d, got %d", task.Version+1, updated.Version)
+	}
+
+	// Versão antiga: nada muda
+	_, err = service.PatchTask(ctx, task.ID, PatchTaskInput{Title: Some("Stale")}, task.Version)
+	if !errors.Is(err, ErrPrecondition) {
+		t.Errorf("expected ErrPrecondition, got %v", err)
+	}
+	if got, _ := service.GetTask(ctx, task.ID); got.Title != "Deploy v2" {
+		t.Errorf("expected title kept, got %q", got.Title)
+	}
+
+	// Escrita concorrente entre a leitura e o UPDATE: sem If-Match é conflito
+	stale, _ := repo.FindByID(ctx, task.ID)
+	if _, err := service.PatchTask(ctx, task.ID, PatchTaskInput{Priority: Some(model.PriorityHigh)}, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := versionError(repo.Update(ctx, stale), 0); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for stale write, got %v", err)
+	}
+	if err := versionError(repo.Update(ctx, stale), stale.Version); !errors.Is(err, ErrPrecondition) {
+		t.Errorf("expected ErrPrecondition for stale write with If-Match, got %v", err)
+	}
+}
+
+func TestUpsertTask(t *testing.T) {
+	service := &TaskService{repo: newTestRepo()}
+	ctx := context.Background()
+	id := "3f2b8c1e-7a4d-4e5f-9b6a-1c2d3e4f5a6b"
+
+	// Id novo: cria a task com o id do cliente, já no status informado
+	task, created, err := service.UpsertTask(ctx, id, UpdateTaskInput{Title: "Sync", Status: model.StatusCompleted, Priority: model.PriorityHigh}, 0)
+	if err != nil || !created {
+		t.Fatalf("expected task created, got created=%v (%v)", created, err)
+	}
+	if task.ID != id || task.CompletedAt == nil || task.Version != 1 {
+		t.Errorf("expected completed task with client id, got %+v", task)
+	}
+
+	// Mesmo id: substitui
+	task, created, err = service.UpsertTask(ctx, id, UpdateTaskInput{Title: "Sync v2", Status: model.StatusCompleted, Priority: model.PriorityLow}, 0)
+	if err != nil || created || task.Title != "Sync v2" {
+		t.Fatalf("expected task replaced, got created=%v %+v (%v)", created, task, err)
+	}
+
+	// Concorrência: a task foi criada entre a busca e o INSERT
+	if _, err := service.createWithID(ctx, id, UpdateTaskInput{Title: "Sync", Status: model.StatusPending, Priority: model.PriorityLow}); !errors.Is(err, errTaskExists) {
+		t.Errorf("expected errTaskExists, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		version int64
+		want    error
+	}{
+		{"id is not a uuid", "task-1", 0, ErrValidation},
+		{"uppercase uuid", "3F2B8C1E-7A4D-4E5F-9B6A-1C2D3E4F5A6C", 0, ErrValidation},
+		{"if-match on missing task", "9a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d", 1, ErrPrecondition},
+		{"if-match * on missing task", "9a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d", AnyVersion, ErrPrecondition},
+		{"if-match * on existing task", id, AnyVersion, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := service.UpsertTask(ctx, tt.id, UpdateTaskInput{Title: "x", Status: model.StatusPending, Priority: model.PriorityLow}, tt.version)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Id na lixeira não é recriado
+	if err := service.DeleteTask(ctx, id, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := service.UpsertTask(ctx, id, UpdateTaskInput{Title: "x", Status: model.StatusPending, Priority: model.PriorityLow}, 0); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for id in trash, got %v", err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+	setupTask(t, repo, &model.Task{ID: "1", Title: "Existing", Status: model.StatusPending, Priority: model.PriorityLow})
+
+	ops := []BatchOperation{
+		{Op: BatchCreate, Create: CreateTaskInput{Title: "New"}},
+		{Op: BatchUpdate, ID: "1", Patch: PatchTaskInput{Title: Some("Renamed")}},
+		{Op: BatchComplete, ID: "missing"},
+		{Op: BatchDelete, ID: "1"},
+	}
+
+	// atomic: a operação 2 falha e o lote inteiro é desfeito
+	results, err := service.Batch(ctx, BatchAtomic, ops)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantAtomic := []error{ErrNotApplied, ErrNotApplied, ErrNotFound, ErrNotApplied}
+	for i, want := range wantAtomic {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("atomic op %d: expected %v, got %v", i, want, results[i].Err)
+		}
+	}
+	if page, _ := service.ListTask(ctx, ListQuery{}); len(page.Items) != 1 || page.Items[0].Title != "Existing" {
+		t.Errorf("expected batch rolled back, got %+v", page.Items)
+	}
+
+	// best_effort: cada operação vale por si
+	results, err = service.Batch(ctx, BatchBestEffort, ops)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Err != nil || results[0].Task == nil || results[1].Err != nil || results[1].Task.Title != "Renamed" {
+		t.Errorf("expected create and update applied, got %+v", results[:2])
+	}
+	if !errors.Is(results[2].Err, ErrNotFound) || results[3].Err != nil {
+		t.Errorf("expected only complete to fail, got %+v", results[2:])
+	}
+	if page, _ := service.ListTask(ctx, ListQuery{}); len(page.Items) != 1 || page.Items[0].Title != "New" {
+		t.Errorf("expected only the created task left, got %+v", page.Items)
+	}
+
+	// Lote inválido não executa nada
+	_, err = service.Batch(ctx, "parallel", []BatchOperation{{Op: "move"}, {Op: BatchDelete}})
+	var vErr *Error
+	if !errors.As(err, &vErr) || vErr.Kind != ErrValidation || len(vErr.Fields) != 3 {
+		t.Errorf("expected 3 field errors, got %v", err)
+	}
+}
+
+// slowReadRepo demora a devolver a task lida, para as requisições concorrentes se intercalarem
+type slowReadRepo struct {
+	*repository.MemoryTaskRepository
+}
+
+func (r slowReadRepo) FindByID(ctx context.Context, id string) (*model.Task, error) {
+	task, err := r.MemoryTaskRepository.FindByID(ctx, id)
+	time.Sleep(time.Millisecond)
+	return task, err
+}
+
+func (r slowReadRepo) FindByIDForUpdate(ctx context.Context, id string) (*model.Task, error) {
+	task, err := r.MemoryTaskRepository.FindByIDForUpdate(ctx, id)
+	time.Sleep(time.Millisecond)
+	return task, err
+}
+
+func TestPatchTask_Concurrent(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: slowReadRepo{repo}}
+	setupTask(t, repo, &model.Task{ID: "1", Title: "Task", Status: model.StatusPending, Priority: model.PriorityLow, Version: 1})
+
+	// Sem a transação, as leituras se intercalavam e a maioria caía no conflito de versão.
+	// Com o lock, cada requisição espera a anterior e todas são aplicadas.
+	const writers = 20
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := service.PatchTask(context.Background(), "1", PatchTaskInput{Title: Some(fmt.Sprintf("Task %d", i))}, 0)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if task, _ := repo.FindByID(context.Background(), "1"); task.Version != writers+1 {
+		t.Errorf("expected version %d, got %d", writers+1, task.Version)
+	}
+}
+
+func TestExportTasks(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+	ctx := context.Background()
+
+	// Mais tasks que uma página do Stream, para passar pelo cursor
+	now := time.Now()
+	for i := 0; i < 501; i++ {
+		priority := model.PriorityLow
+		if i%2 == 0 {
+			priority = model.PriorityHigh
+		}
+		setupTask(t, repo, &model.Task{ID: fmt.Sprintf("%04d", i), Title: "Task", Status: model.StatusPending, Priority: priority, CreatedAt: now})
+	}
+
+	var ids []string
+	err := service.ExportTasks(ctx, model.TaskFilter{Priorities: []string{model.PriorityHigh}}, func(task model.Task) error {
+		ids = append(ids, task.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 251 || ids[0] != "0000" || ids[250] != "0500" {
+		t.Errorf("expected the 251 high priority tasks in order, got %d (%v...)", len(ids), ids[:min(len(ids), 3)])
+	}
+
+	// Filtro inválido falha antes de chamar fn
+	err = service.ExportTasks(ctx, model.TaskFilter{Statuses: []string{"done"}}, func(model.Task) error {
+		t.Fatal("fn called with invalid filter")
+		return nil
+	})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+}
+
+func TestImportTasks(t *testing.T) {
+	repo := newTestRepo()
+	service := &TaskService{repo: repo}
+	ctx := context.Background()
+	parentID := "3f2b8c1e-7a4d-4e5f-9b6a-1c2d3e4f5a6b"
+
+	rows := []ImportRow{
+		{Line: 2, ID: parentID, Task: UpdateTaskInput{Title: "Parent", Tags: []string{"Casa"}}},
+		{Line: 3, Task: UpdateTaskInput{Title: "Child", ParentID: parentID, Priority: model.PriorityHigh}},
+		{Line: 4, Task: UpdateTaskInput{Title: "", Status: "done"}},
+		{Line: 5, Err: invalid("due_at", "invalid due_at")},
+	}
+	want := []string{ImportCreated, ImportCreated, ImportFailed, ImportFailed}
+
+	check := func(results []ImportResult, dryRun bool) {
+		t.Helper()
+		for i, result := range results {
+			if result.Result != want[i] || result.Line != rows[i].Line {
+				t.Errorf("row %d: expected %s, got %+v", i, want[i], result)
+			}
+		}
+		// Id gerado só na linha criada de verdade
+		if (results[1].ID == "") != dryRun || results[2].ID != "" {
+			t.Errorf("expected generated id only for saved rows, got %+v", results)
+		}
+		var vErr *Error
+		if !errors.As(results[2].Err, &vErr) || len(vErr.Fields) != 2 {
+			t.Errorf("expected title and status errors, got %v", results[2].Err)
+		}
+	}
+
+	// dry run: mesmo resultado (a subtask enxerga o pai da linha anterior), nada gravado
+	results, err := service.ImportTasks(ctx, rows, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(results, true)
+	if page, _ := service.ListTask(ctx, ListQuery{}); len(page.Items) != 0 {
+		t.Errorf("expected nothing saved on dry run, got %+v", page.Items)
+	}
+
+	results, err = service.ImportTasks(ctx, rows, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	check(results, false)
+	child, _ := repo.FindByID(ctx, results[1].ID)
+	if child == nil || child.ParentID == nil || *child.ParentID != parentID || child.Status != model.StatusPending {
+		t.Errorf("expected child task under parent, got %+v", child)
+	}
+
+	// Reimportar o mesmo id substitui a task
+	results, _ = service.ImportTasks(ctx, rows[:1], false)
+	if results[0].Result != ImportUpdated {
+		t.Errorf("expected updated, got %+v", results[0])
+	}
+
+	if _, err := service.ImportTasks(ctx, nil, false); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation for empty file, got %v", err)
+	}
+}
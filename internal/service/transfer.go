@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/DinizJ/desafio/internal/model"
+	"github.com/DinizJ/desafio/internal/repository"
+)
+
+// ------------------------EXPORT--------------------------------
+// ExportTasks chama fn para cada task do filtro (o mesmo da listagem), em ordem de
+// criação, sem montar a lista inteira. As tasks saem como estão gravadas, sem os
+// campos calculados.
+func (s *TaskService) ExportTasks(ctx context.Context, filter model.TaskFilter, fn func(model.Task) error) error {
+	if err := validateFilter(&filter); err != nil {
+		return err
+	}
+	// Todas as páginas do Stream usam o mesmo "agora" no filtro overdue
+	if filter.Now.IsZero() {
+		filter.Now = s.now()
+	}
+	return s.repo.Stream(ctx, filter, fn)
+}
+
+// ------------------------IMPORT--------------------------------
+// maxImportRows é o máximo de linhas por importação
+const maxImportRows = 10000
+
+// Resultado de cada linha importada
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportFailed  = "failed"
+)
+
+// ImportRow é uma linha do arquivo importado; Line é a linha no arquivo, para o relatório.
+// Sem ID a task é criada com um id novo; com ID é criada com ele ou, se já existir,
+// substituída, como no PUT /tasks/{id}. Status e priority vazios viram pending e medium.
+// Err é um erro de leitura da linha (ex.: data mal formada): a linha só é reportada.
+type ImportRow struct {
+	Line int
+	ID   string
+	Task UpdateTaskInput
+	Err  error
+}
+
+// ImportResult é o resultado de uma linha, na mesma posição dela
+type ImportResult struct {
+	Line   int
+	ID     string
+	Result string // ImportCreated, ImportUpdated ou ImportFailed
+	Err    error
+}
+
+// errDryRun desfaz a transação de cada linha da simulação
+var errDryRun = errors.New("dry run")
+
+// ImportTasks aplica as linhas na ordem, cada uma com as regras do PUT /tasks/{id},
+// menos a de prazo no passado: a task pode vir de outro ambiente já atrasada.
+// Uma linha inválida não impede as outras. Com dryRun cada linha roda numa transação
+// curta que é desfeita em seguida: o resultado é o que a importação faria, sem gravar nada.
+func (s *TaskService) ImportTasks(ctx context.Context, rows []ImportRow, dryRun bool) ([]ImportResult, error) {
+	switch {
+	case len(rows) == 0:
+		return nil, invalid("", "file has no tasks")
+	case len(rows) > maxImportRows:
+		return nil, invalid("", "too many tasks (max %d)", maxImportRows)
+	}
+
+	imp := *s
+	imp.importing = true
+
+	results := make([]ImportResult, len(rows))
+	if !dryRun {
+		for i, row := range rows {
+			results[i] = imp.importRow(ctx, row)
+		}
+		return results, nil
+	}
+
+	// Linhas com id criadas na simulação, para as subtasks das linhas seguintes
+	created := map[string]ImportRow{}
+	for i, row := range rows {
+		result, err := imp.dryRunRow(ctx, row, created)
+		if err != nil {
+			return nil, err
+		}
+		if result.Result == ImportCreated && row.ID != "" {
+			created[row.ID] = row
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// dryRunRow aplica a linha numa transação desfeita no fim. Se o pai (ou um ancestral),
+// ou a própria task, só foi criado numa linha anterior da simulação, ele é recriado antes
+// na mesma transação.
+func (s *TaskService) dryRunRow(ctx context.Context, row ImportRow, created map[string]ImportRow) (ImportResult, error) {
+	var result ImportResult
+	err := s.repo.WithinTx(ctx, func(repo repository.TaskRepositoryInterface) error {
+		tx := s.withRepo(repo)
+
+		var ancestors []ImportRow
+		for id := row.Task.ParentID; len(ancestors) < maxDepth; {
+			parent, ok := created[id]
+			if !ok {
+				break
+			}
+			ancestors = append(ancestors, parent)
+			id = parent.Task.ParentID
+		}
+		for i := len(ancestors) - 1; i >= 0; i-- {
+			tx.importRow(ctx, ancestors[i])
+		}
+		if previous, ok := created[row.ID]; ok {
+			tx.importRow(ctx, previous)
+		}
+
+		result = tx.importRow(ctx, row)
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return ImportResult{}, err
+	}
+
+	// O id gerado para a linha foi desfeito junto com ela
+	if row.ID == "" {
+		result.ID = ""
+	}
+	return result, nil
+}
+
+func (s *TaskService) importRow(ctx context.Context, row ImportRow) ImportResult {
+	result := ImportResult{Line: row.Line, ID: row.ID, Result: ImportFailed}
+	if row.Err != nil {
+		result.Err = row.Err
+		return result
+	}
+
+	if result.ID == "" {
+		result.ID = uuid.NewString()
+	}
+	in := row.Task
+	if in.Status == "" {
+		in.Status = model.StatusPending
+	}
+	if in.Priority == "" {
+		in.Priority = model.PriorityMedium
+	}
+
+	_, created, err := s.UpsertTask(ctx, result.ID, in, 0)
+	switch {
+	case err != nil:
+		result.Err = err
+		// id gerado aqui não existe para o cliente
+		if row.ID == "" {
+			result.ID = ""
+		}
+	case created:
+		result.Result = ImportCreated
+	default:
+		result.Result = ImportUpdated
+	}
+	return result
+}
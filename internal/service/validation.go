@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DinizJ/desafio/internal/model"
+)
+
+// Validação declarativa dos DTOs de entrada. Cada campo declara suas regras na tag
+// `validate`, separadas por vírgula:
+//
+//	required   string com algo além de espaços; ponteiro ou lista não nil
+//	max=N      string com até N caracteres; lista com até N itens
+//	enum=nome  valor (ou cada item da lista) em enums[nome]; vazio passa
+//
+// Em campos Optional as regras só valem quando o campo está presente; null
+// explícito só falha em required. O nome do campo nos erros é o da tag json. Todos os campos são verificados
+// e os erros voltam juntos; regras que dependem do banco ficam no service.
+
+// enums são os conjuntos aceitos por enum=nome
+var enums = map[string][]string{
+	"status":     model.Statuses,
+	"priority":   model.Priorities,
+	"tag_mode":   {model.TagMatchAny, model.TagMatchAll},
+	"batch_mode": {BatchAtomic, BatchBestEffort},
+	"batch_op":   {BatchCreate, BatchUpdate, BatchDelete, BatchComplete},
+}
+
+// validationErrors junta os campos inválidos de uma requisição
+type validationErrors []FieldError
+
+func (v *validationErrors) add(field, format string, args ...any) {
+	*v = append(*v, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// addErr inclui os campos de um erro de validação; devolve os demais erros
+// (banco, conflito) para o chamador interromper a operação
+func (v *validationErrors) addErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) || e.Kind != ErrValidation {
+		return err
+	}
+	if len(e.Fields) == 0 {
+		v.add("", "%s", e.Message)
+		return nil
+	}
+	*v = append(*v, e.Fields...)
+	return nil
+}
+
+// err devolve nil sem campos inválidos; a mensagem junta as de todos os campos
+func (v validationErrors) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	messages := make([]string, len(v))
+	for i, f := range v {
+		messages[i] = f.Message
+	}
+	return &Error{Kind: ErrValidation, Message: strings.Join(messages, "; "), Fields: v}
+}
+
+// checkStruct aplica as regras das tags `validate` de input (struct ou ponteiro para struct)
+func (v *validationErrors) checkStruct(input any) {
+	value := reflect.Indirect(reflect.ValueOf(input))
+	typ := value.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		rules := typ.Field(i).Tag.Get("validate")
+		if rules == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = typ.Field(i).Name
+		}
+
+		field := value.Field(i)
+		if opt, ok := field.Interface().(optionalField); ok {
+			present, null, inner := opt.state()
+			if !present {
+				continue
+			}
+			if null {
+				if slices.Contains(strings.Split(rules, ","), "required") {
+					v.add(name, "%s cannot be null", name)
+				}
+				continue
+			}
+			field = inner
+		}
+
+		for _, rule := range strings.Split(rules, ",") {
+			key, arg, _ := strings.Cut(rule, "=")
+			// Um erro por campo: a primeira regra que falha
+			if !v.checkRule(name, key, arg, field) {
+				break
+			}
+		}
+	}
+}
+
+// checkRule valida uma regra; regra desconhecida na tag é erro de programação
+func (v *validationErrors) checkRule(name, rule, arg string, field reflect.Value) bool {
+	switch rule {
+	case "required":
+		if isBlank(field) {
+			v.add(name, "%s is required", name)
+			return false
+		}
+
+	case "max":
+		limit, err := strconv.Atoi(arg)
+		if err != nil {
+			panic(fmt.Sprintf("validate: max inválido em %s: %q", name, arg))
+		}
+		value := reflect.Indirect(field)
+		switch {
+		case value.Kind() == reflect.String && utf8.RuneCountInString(value.String()) > limit:
+			v.add(name, "%s is too long (max %d)", name, limit)
+			return false
+		case value.Kind() == reflect.Slice && value.Len() > limit:
+			v.add(name, "too many %s (max %d)", name, limit)
+			return false
+		}
+
+	case "enum":
+		return v.checkEnum(name, arg, stringValues(field)...)
+
+	default:
+		panic(fmt.Sprintf("validate: regra desconhecida em %s: %q", name, rule))
+	}
+	return true
+}
+
+// checkEnum confere se cada valor não vazio está em enums[enum]
+func (v *validationErrors) checkEnum(name, enum string, values ...string) bool {
+	allowed, ok := enums[enum]
+	if !ok {
+		panic(fmt.Sprintf("validate: enum desconhecido em %s: %q", name, enum))
+	}
+	for _, value := range values {
+		if value != "" && !slices.Contains(allowed, value) {
+			v.add(name, "invalid %s %q: must be one of %s", name, value, strings.Join(allowed, ", "))
+			return false
+		}
+	}
+	return true
+}
+
+func isBlank(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(field.String()) == ""
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		return field.IsNil()
+	}
+	return field.IsZero()
+}
+
+// stringValues lê string, *string ou []string como lista
+func stringValues(field reflect.Value) []string {
+	value := reflect.Indirect(field)
+	switch value.Kind() {
+	case reflect.String:
+		return []string{value.String()}
+	case reflect.Slice:
+		values := make([]string, value.Len())
+		for i := range values {
+			values[i] = value.Index(i).String()
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Package sqlutil reúne o que o repository e o migrate precisam igual para
+// falar com os bancos suportados.
+package sqlutil
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rebind troca os placeholders "?" (formato do MySQL e do SQLite) por "$1, $2, ...",
+// o formato do PostgreSQL. Quem chama decide se o banco precisa da troca.
+func Rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,8 @@
+// Package migrations embute os scripts SQL no binário.
+// Cada driver tem seu diretório, com arquivos no formato NNN_nome.up.sql / NNN_nome.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var FS embed.FS
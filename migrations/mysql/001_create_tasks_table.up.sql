@@ -18,7 +18,3 @@ CREATE TABLE IF NOT EXISTS tasks (
 
 -- Criar índice composto para busca por status e prioridade
 CREATE INDEX idx_status_priority ON tasks(status, priority);
-
--- Verificar criação
-SHOW TABLES;
-DESCRIBE tasks;
@@ -0,0 +1,6 @@
+-- Migration 002 (down): Soft delete de tasks
+
+DROP INDEX idx_deleted_at ON tasks;
+
+ALTER TABLE tasks
+    MODIFY deleted_at TIMESTAMP NULL DEFAULT NULL COMMENT 'Data de deleção (soft delete - não implementado ainda)';
@@ -0,0 +1,8 @@
+-- Migration 002: Soft delete de tasks
+-- deleted_at passa a ser usado: DELETE manda a task para a lixeira
+
+ALTER TABLE tasks
+    MODIFY deleted_at TIMESTAMP NULL DEFAULT NULL COMMENT 'Data de deleção (soft delete, NULL = task ativa)';
+
+-- Toda listagem filtra por deleted_at IS NULL, e a lixeira ordena por deleted_at
+CREATE INDEX idx_deleted_at ON tasks(deleted_at);
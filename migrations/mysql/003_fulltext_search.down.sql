@@ -0,0 +1,3 @@
+-- Migration 003 (down): Full-text search
+
+ALTER TABLE tasks DROP INDEX ft_title_description;
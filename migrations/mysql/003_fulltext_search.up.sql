@@ -0,0 +1,4 @@
+-- Migration 003: Full-text search
+-- Índice FULLTEXT usado pelo GET /api/v1/tasks/search (MATCH ... AGAINST)
+
+ALTER TABLE tasks ADD FULLTEXT INDEX ft_title_description (title, description);
@@ -0,0 +1,8 @@
+-- Migration 004: Data de vencimento das tasks
+-- DATETIME (e não TIMESTAMP) para aceitar prazos depois de 2038; a aplicação grava sempre em UTC
+
+ALTER TABLE tasks
+    ADD COLUMN due_at DATETIME NULL DEFAULT NULL COMMENT 'Prazo da task em UTC (NULL = sem prazo)' AFTER priority;
+
+-- Filtros de prazo, overdue e a visão de próximas tasks
+CREATE INDEX idx_due_at ON tasks(due_at);
@@ -0,0 +1,11 @@
+-- Migration 005: Tags das tasks (muitos-para-muitos)
+-- As tags já chegam normalizadas pelo service (minúsculas, sem espaços)
+
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id VARCHAR(36) NOT NULL COMMENT 'Task marcada',
+    tag VARCHAR(50) NOT NULL COMMENT 'Tag normalizada',
+    PRIMARY KEY (task_id, tag),
+    -- Filtro por tag e contagem de uso partem da tag
+    INDEX idx_task_tags_tag (tag),
+    CONSTRAINT fk_task_tags_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Tags de cada task';
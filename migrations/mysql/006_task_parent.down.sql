@@ -0,0 +1,7 @@
+-- Migration 006 (down): Subtasks
+
+ALTER TABLE tasks DROP FOREIGN KEY fk_tasks_parent;
+
+DROP INDEX idx_parent_id ON tasks;
+
+ALTER TABLE tasks DROP COLUMN parent_id;
@@ -0,0 +1,11 @@
+-- Migration 006: Subtasks (hierarquia pai/filho)
+-- Purge do pai solta os filhos (viram tasks raiz); o repository também faz isso explicitamente
+
+ALTER TABLE tasks
+    ADD COLUMN parent_id VARCHAR(36) NULL DEFAULT NULL COMMENT 'Task pai (NULL = task raiz)' AFTER id;
+
+-- Listagem de subtasks e contagem de progresso partem do pai
+CREATE INDEX idx_parent_id ON tasks(parent_id);
+
+ALTER TABLE tasks
+    ADD CONSTRAINT fk_tasks_parent FOREIGN KEY (parent_id) REFERENCES tasks(id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+-- Migration 007 (down): Dependências entre tasks
+
+DROP TABLE IF EXISTS task_dependencies;
@@ -0,0 +1,13 @@
+-- Migration 007: Dependências entre tasks (task_id é bloqueada por blocker_id)
+-- Ciclos são rejeitados pelo service antes do INSERT
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id VARCHAR(36) NOT NULL COMMENT 'Task bloqueada',
+    blocker_id VARCHAR(36) NOT NULL COMMENT 'Task que precisa ser concluída antes',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Data de criação da dependência',
+    PRIMARY KEY (task_id, blocker_id),
+    -- Lista "blocking": o que esta task está bloqueando
+    INDEX idx_task_dependencies_blocker (blocker_id),
+    CONSTRAINT fk_task_dependencies_task FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_dependencies_blocker FOREIGN KEY (blocker_id) REFERENCES tasks(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Grafo de dependências entre tasks';
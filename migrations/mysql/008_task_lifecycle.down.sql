@@ -0,0 +1,9 @@
+-- Migration 008 (down): Ciclo de vida das tasks
+-- Os status novos não existem na versão anterior: tasks em andamento ou bloqueadas
+-- voltam para pending, canceladas e arquivadas viram completed (perda de informação)
+
+UPDATE tasks SET status = 'pending' WHERE status IN ('in_progress', 'blocked');
+UPDATE tasks SET status = 'completed' WHERE status IN ('cancelled', 'archived');
+
+ALTER TABLE tasks
+    MODIFY status ENUM('pending', 'completed') NOT NULL DEFAULT 'pending' COMMENT 'Status atual da tarefa';
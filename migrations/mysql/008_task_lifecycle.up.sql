@@ -0,0 +1,7 @@
+-- Migration 008: Ciclo de vida das tasks (máquina de estados)
+-- Novos status: in_progress, blocked, cancelled e archived.
+-- As transições permitidas ficam no service; o banco só garante os valores válidos.
+
+ALTER TABLE tasks
+    MODIFY status ENUM('pending', 'in_progress', 'blocked', 'completed', 'cancelled', 'archived')
+        NOT NULL DEFAULT 'pending' COMMENT 'Status atual da tarefa';
@@ -0,0 +1,6 @@
+-- Migration 009 (down): Conclusão e reabertura de tasks
+
+ALTER TABLE tasks
+    DROP COLUMN reopened_by,
+    DROP COLUMN reopened_at,
+    DROP COLUMN completed_at;
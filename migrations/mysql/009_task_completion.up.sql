@@ -0,0 +1,11 @@
+-- Migration 009: Conclusão e reabertura de tasks
+-- completed_at é gravado ao concluir e limpo ao reabrir; reopened_at/reopened_by
+-- guardam a última reabertura. DATETIME em UTC, como o due_at.
+
+ALTER TABLE tasks
+    ADD COLUMN completed_at DATETIME NULL DEFAULT NULL COMMENT 'Quando a task foi concluída (NULL = não concluída)' AFTER due_at,
+    ADD COLUMN reopened_at DATETIME NULL DEFAULT NULL COMMENT 'Última reabertura' AFTER completed_at,
+    ADD COLUMN reopened_by VARCHAR(100) NULL DEFAULT NULL COMMENT 'Quem reabriu por último (header X-User)' AFTER reopened_at;
+
+-- Tasks já concluídas: a melhor estimativa disponível é a última atualização
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed';
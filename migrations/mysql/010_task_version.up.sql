@@ -0,0 +1,6 @@
+-- Migration 010: Versão da task (controle de concorrência otimista)
+-- Cada UPDATE incrementa a versão e só é aplicado se ela não mudou desde a leitura;
+-- o ETag das respostas é a versão.
+
+ALTER TABLE tasks
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1 COMMENT 'Incrementada a cada alteração (ETag)' AFTER reopened_by;
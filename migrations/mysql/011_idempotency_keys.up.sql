@@ -0,0 +1,15 @@
+-- Migration 011: Chaves de idempotência (header Idempotency-Key)
+-- Cada chave guarda a impressão digital da requisição e a resposta, para repetir
+-- a resposta quando o cliente reenvia. Chaves vencidas são apagadas na próxima reserva.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) NOT NULL PRIMARY KEY COMMENT 'Valor do header Idempotency-Key',
+    fingerprint CHAR(64) NOT NULL COMMENT 'SHA-256 do método, caminho e corpo',
+    status_code INT NOT NULL DEFAULT 0 COMMENT 'Status da resposta (0 = requisição em andamento)',
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    etag VARCHAR(64) NOT NULL DEFAULT '',
+    body MEDIUMBLOB NULL COMMENT 'Corpo da resposta',
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL COMMENT 'Depois disso a chave pode ser reutilizada',
+    INDEX idx_idempotency_keys_expires (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Respostas de requisições idempotentes';
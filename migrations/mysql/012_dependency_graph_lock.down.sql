@@ -0,0 +1,3 @@
+-- Migration 012 (down): Lock do grafo de dependências
+
+DROP TABLE IF EXISTS dependency_graph_lock;
@@ -0,0 +1,9 @@
+-- Migration 012: Lock do grafo de dependências
+-- Uma linha só: quem vai alterar task_dependencies trava esta linha (SELECT ... FOR UPDATE)
+-- antes de ler o grafo, então duas arestas nunca são checadas contra ciclo ao mesmo tempo.
+
+CREATE TABLE IF NOT EXISTS dependency_graph_lock (
+    id INT NOT NULL PRIMARY KEY COMMENT 'Sempre 1'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Lock das alterações em task_dependencies';
+
+INSERT IGNORE INTO dependency_graph_lock (id) VALUES (1);
@@ -0,0 +1,7 @@
+-- Migration 013: Dono da reserva de idempotência
+-- Cada reserva grava um id aleatório de quem a fez; a resposta só é gravada (ou a chave
+-- liberada) por quem ainda tem a reserva. Se ela venceu e outra requisição reservou a
+-- mesma chave, a primeira não sobrescreve nem apaga o registro da segunda.
+
+ALTER TABLE idempotency_keys
+    ADD COLUMN owner VARCHAR(36) NOT NULL DEFAULT '' COMMENT 'Id aleatório da requisição que reservou a chave' AFTER fingerprint;
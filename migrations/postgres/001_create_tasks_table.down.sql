@@ -0,0 +1,3 @@
+-- Migration 001 (down): Drop tasks table
+
+DROP TABLE IF EXISTS tasks;
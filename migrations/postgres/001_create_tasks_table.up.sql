@@ -0,0 +1,27 @@
+-- Migration 001 (PostgreSQL): Create tasks table
+-- Equivalente a migrations/mysql/001_create_tasks_table.up.sql.
+-- id usa o tipo UUID nativo e as datas são timestamptz.
+-- status e priority usam CHECK em vez de ENUM para facilitar migrações futuras
+-- (ALTER TYPE ... ADD VALUE não pode ser desfeito).
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id UUID PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CONSTRAINT chk_tasks_status CHECK (status IN ('pending', 'completed')),
+    priority VARCHAR(10) NOT NULL DEFAULT 'medium'
+        CONSTRAINT chk_tasks_priority CHECK (priority IN ('low', 'medium', 'high')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ NULL DEFAULT NULL
+);
+
+COMMENT ON TABLE tasks IS 'Tabela de tarefas (TODO list)';
+
+CREATE INDEX IF NOT EXISTS idx_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_priority ON tasks(priority);
+CREATE INDEX IF NOT EXISTS idx_created_at ON tasks(created_at);
+
+-- Índice composto para busca por status e prioridade
+CREATE INDEX IF NOT EXISTS idx_status_priority ON tasks(status, priority);
@@ -0,0 +1,6 @@
+-- Migration 002 (PostgreSQL): Soft delete de tasks
+-- Equivalente a migrations/mysql/002_soft_delete_tasks.up.sql
+
+COMMENT ON COLUMN tasks.deleted_at IS 'Data de deleção (soft delete, NULL = task ativa)';
+
+CREATE INDEX IF NOT EXISTS idx_deleted_at ON tasks(deleted_at);
@@ -0,0 +1,3 @@
+-- Migration 003: Full-text search
+-- Sem índice full-text neste banco: a busca usa LIKE por termo (ver TaskRepository.Search).
+-- O arquivo existe para manter a numeração de versões igual entre os drivers.
@@ -0,0 +1,8 @@
+-- Migration 004 (PostgreSQL): Data de vencimento das tasks
+-- Equivalente a migrations/mysql/004_task_due_dates.up.sql
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ NULL;
+
+COMMENT ON COLUMN tasks.due_at IS 'Prazo da task (NULL = sem prazo)';
+
+CREATE INDEX IF NOT EXISTS idx_due_at ON tasks(due_at);
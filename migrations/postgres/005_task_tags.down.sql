@@ -0,0 +1,3 @@
+-- Migration 005 (down): Tags das tasks
+
+DROP TABLE IF EXISTS task_tags;
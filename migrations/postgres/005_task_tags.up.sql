@@ -0,0 +1,12 @@
+-- Migration 005 (PostgreSQL): Tags das tasks (muitos-para-muitos)
+-- Equivalente a migrations/mysql/005_task_tags.up.sql
+
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (task_id, tag)
+);
+
+COMMENT ON TABLE task_tags IS 'Tags de cada task';
+
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(tag);
@@ -0,0 +1,10 @@
+-- Migration 006 (PostgreSQL): Subtasks (hierarquia pai/filho)
+-- Equivalente a migrations/mysql/006_task_parent.up.sql
+
+ALTER TABLE tasks
+    ADD COLUMN IF NOT EXISTS parent_id UUID NULL
+        CONSTRAINT fk_tasks_parent REFERENCES tasks(id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN tasks.parent_id IS 'Task pai (NULL = task raiz)';
+
+CREATE INDEX IF NOT EXISTS idx_parent_id ON tasks(parent_id);
@@ -0,0 +1,13 @@
+-- Migration 007 (PostgreSQL): Dependências entre tasks
+-- Equivalente a migrations/mysql/007_task_dependencies.up.sql
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocker_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, blocker_id)
+);
+
+COMMENT ON TABLE task_dependencies IS 'Grafo de dependências entre tasks';
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocker ON task_dependencies(blocker_id);
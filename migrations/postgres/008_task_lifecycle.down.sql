@@ -0,0 +1,9 @@
+-- Migration 008 (down): Ciclo de vida das tasks
+-- Mesmo mapeamento de migrations/mysql/008_task_lifecycle.down.sql
+
+UPDATE tasks SET status = 'pending' WHERE status IN ('in_progress', 'blocked');
+UPDATE tasks SET status = 'completed' WHERE status IN ('cancelled', 'archived');
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS chk_tasks_status;
+
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_status CHECK (status IN ('pending', 'completed'));
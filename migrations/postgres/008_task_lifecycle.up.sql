@@ -0,0 +1,7 @@
+-- Migration 008 (PostgreSQL): Ciclo de vida das tasks (máquina de estados)
+-- Equivalente a migrations/mysql/008_task_lifecycle.up.sql: troca o CHECK do status
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS chk_tasks_status;
+
+ALTER TABLE tasks ADD CONSTRAINT chk_tasks_status
+    CHECK (status IN ('pending', 'in_progress', 'blocked', 'completed', 'cancelled', 'archived'));
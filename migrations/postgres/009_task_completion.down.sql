@@ -0,0 +1,5 @@
+-- Migration 009 (down): Conclusão e reabertura de tasks
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS reopened_by;
+ALTER TABLE tasks DROP COLUMN IF EXISTS reopened_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
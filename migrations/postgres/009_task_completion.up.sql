@@ -0,0 +1,11 @@
+-- Migration 009 (PostgreSQL): Conclusão e reabertura de tasks
+-- Equivalente a migrations/mysql/009_task_completion.up.sql
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS reopened_at TIMESTAMPTZ NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS reopened_by VARCHAR(100) NULL;
+
+COMMENT ON COLUMN tasks.completed_at IS 'Quando a task foi concluída (NULL = não concluída)';
+COMMENT ON COLUMN tasks.reopened_by IS 'Quem reabriu por último (header X-User)';
+
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed';
@@ -0,0 +1,3 @@
+-- Migration 010 (down): Versão da task
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
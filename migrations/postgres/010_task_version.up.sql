@@ -0,0 +1,6 @@
+-- Migration 010 (PostgreSQL): Versão da task (controle de concorrência otimista)
+-- Equivalente a migrations/mysql/010_task_version.up.sql
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN tasks.version IS 'Incrementada a cada alteração (ETag)';
@@ -0,0 +1,3 @@
+-- Migration 011 (down): Chaves de idempotência
+
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,18 @@
+-- Migration 011 (PostgreSQL): Chaves de idempotência (header Idempotency-Key)
+-- Equivalente a migrations/mysql/011_idempotency_keys.up.sql
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key VARCHAR(255) NOT NULL PRIMARY KEY,
+    fingerprint CHAR(64) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    etag VARCHAR(64) NOT NULL DEFAULT '',
+    body BYTEA NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+COMMENT ON TABLE idempotency_keys IS 'Respostas de requisições idempotentes';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'Status da resposta (0 = requisição em andamento)';
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys(expires_at);
@@ -0,0 +1,10 @@
+-- Migration 012 (PostgreSQL): Lock do grafo de dependências
+-- Equivalente a migrations/mysql/012_dependency_graph_lock.up.sql
+
+CREATE TABLE IF NOT EXISTS dependency_graph_lock (
+    id INTEGER NOT NULL PRIMARY KEY
+);
+
+COMMENT ON TABLE dependency_graph_lock IS 'Lock das alterações em task_dependencies';
+
+INSERT INTO dependency_graph_lock (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
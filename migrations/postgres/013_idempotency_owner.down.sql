@@ -0,0 +1,3 @@
+-- Migration 013 (down): Dono da reserva de idempotência
+
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS owner;
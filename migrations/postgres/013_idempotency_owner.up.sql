@@ -0,0 +1,6 @@
+-- Migration 013 (PostgreSQL): Dono da reserva de idempotência
+-- Equivalente a migrations/mysql/013_idempotency_owner.up.sql
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS owner VARCHAR(36) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN idempotency_keys.owner IS 'Id aleatório da requisição que reservou a chave';
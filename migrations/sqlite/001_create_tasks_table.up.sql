@@ -0,0 +1,25 @@
+-- Migration 001 (SQLite): Create tasks table
+-- Equivalente a migrations/mysql/001_create_tasks_table.up.sql.
+-- SQLite não tem ENUM: status e priority são validados por CHECK.
+-- Datas são gravadas como texto em UTC pelo driver e lidas de volta como time.Time
+-- (o tipo declarado DATETIME é o que faz o driver converter na leitura).
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id TEXT PRIMARY KEY CHECK (length(id) <= 36),
+    title TEXT NOT NULL CHECK (length(title) <= 255),
+    description TEXT,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CONSTRAINT chk_tasks_status CHECK (status IN ('pending', 'completed')),
+    priority TEXT NOT NULL DEFAULT 'medium'
+        CONSTRAINT chk_tasks_priority CHECK (priority IN ('low', 'medium', 'high')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME NULL DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_priority ON tasks(priority);
+CREATE INDEX IF NOT EXISTS idx_created_at ON tasks(created_at);
+
+-- Índice composto para busca por status e prioridade
+CREATE INDEX IF NOT EXISTS idx_status_priority ON tasks(status, priority);
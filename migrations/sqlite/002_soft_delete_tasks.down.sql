@@ -0,0 +1,3 @@
+-- Migration 002 (down): Soft delete de tasks
+
+DROP INDEX IF EXISTS idx_deleted_at;
@@ -0,0 +1,4 @@
+-- Migration 002 (SQLite): Soft delete de tasks
+-- Equivalente a migrations/mysql/002_soft_delete_tasks.up.sql
+
+CREATE INDEX IF NOT EXISTS idx_deleted_at ON tasks(deleted_at);
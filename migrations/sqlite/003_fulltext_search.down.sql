@@ -0,0 +1,2 @@
+-- Migration 003 (down): Full-text search
+-- Nada a desfazer.
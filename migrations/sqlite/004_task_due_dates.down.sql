@@ -0,0 +1,5 @@
+-- Migration 004 (down): Data de vencimento das tasks
+
+DROP INDEX IF EXISTS idx_due_at;
+
+ALTER TABLE tasks DROP COLUMN due_at;
@@ -0,0 +1,6 @@
+-- Migration 004 (SQLite): Data de vencimento das tasks
+-- Equivalente a migrations/mysql/004_task_due_dates.up.sql
+
+ALTER TABLE tasks ADD COLUMN due_at DATETIME NULL;
+
+CREATE INDEX IF NOT EXISTS idx_due_at ON tasks(due_at);
@@ -0,0 +1,11 @@
+-- Migration 005 (SQLite): Tags das tasks (muitos-para-muitos)
+-- Equivalente a migrations/mysql/005_task_tags.up.sql.
+-- O ON DELETE CASCADE depende do PRAGMA foreign_keys, ligado pelo OpenSQLite.
+
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL CHECK (length(tag) <= 50),
+    PRIMARY KEY (task_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_tags_tag ON task_tags(tag);
@@ -0,0 +1,5 @@
+-- Migration 006 (down): Subtasks
+
+DROP INDEX IF EXISTS idx_parent_id;
+
+ALTER TABLE tasks DROP COLUMN parent_id;
@@ -0,0 +1,8 @@
+-- Migration 006 (SQLite): Subtasks (hierarquia pai/filho)
+-- Equivalente a migrations/mysql/006_task_parent.up.sql, sem a FOREIGN KEY:
+-- o SQLite não deixa remover (DROP COLUMN) uma coluna com FK, o que impediria o down.
+-- O Purge do repository solta os filhos antes de apagar o pai.
+
+ALTER TABLE tasks ADD COLUMN parent_id TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_parent_id ON tasks(parent_id);
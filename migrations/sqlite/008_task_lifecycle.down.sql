@@ -0,0 +1,45 @@
+-- Migration 008 (down, SQLite): Ciclo de vida das tasks
+-- Mesmo mapeamento de status de migrations/mysql/008_task_lifecycle.down.sql,
+-- seguido da mesma recriação da tabela do up, agora com o CHECK antigo.
+
+UPDATE tasks SET status = 'pending' WHERE status IN ('in_progress', 'blocked');
+UPDATE tasks SET status = 'completed' WHERE status IN ('cancelled', 'archived');
+
+CREATE TEMP TABLE task_tags_backup AS SELECT task_id, tag FROM task_tags;
+CREATE TEMP TABLE task_dependencies_backup AS SELECT task_id, blocker_id, created_at FROM task_dependencies;
+
+CREATE TABLE tasks_new (
+    id TEXT PRIMARY KEY CHECK (length(id) <= 36),
+    title TEXT NOT NULL CHECK (length(title) <= 255),
+    description TEXT,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CONSTRAINT chk_tasks_status CHECK (status IN ('pending', 'completed')),
+    priority TEXT NOT NULL DEFAULT 'medium'
+        CONSTRAINT chk_tasks_priority CHECK (priority IN ('low', 'medium', 'high')),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME NULL DEFAULT NULL,
+    due_at DATETIME NULL,
+    parent_id TEXT NULL
+);
+
+INSERT INTO tasks_new (id, title, description, status, priority, created_at, updated_at, deleted_at, due_at, parent_id)
+    SELECT id, title, description, status, priority, created_at, updated_at, deleted_at, due_at, parent_id FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+
+INSERT INTO task_tags (task_id, tag) SELECT task_id, tag FROM task_tags_backup;
+INSERT INTO task_dependencies (task_id, blocker_id, created_at)
+    SELECT task_id, blocker_id, created_at FROM task_dependencies_backup;
+
+DROP TABLE task_tags_backup;
+DROP TABLE task_dependencies_backup;
+
+CREATE INDEX IF NOT EXISTS idx_status ON tasks(status);
+CREATE INDEX IF NOT EXISTS idx_priority ON tasks(priority);
+CREATE INDEX IF NOT EXISTS idx_created_at ON tasks(created_at);
+CREATE INDEX IF NOT EXISTS idx_status_priority ON tasks(status, priority);
+CREATE INDEX IF NOT EXISTS idx_deleted_at ON tasks(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_due_at ON tasks(due_at);
+CREATE INDEX IF NOT EXISTS idx_parent_id ON tasks(parent_id);
@@ -0,0 +1,8 @@
+-- Migration 009 (SQLite): Conclusão e reabertura de tasks
+-- Equivalente a migrations/mysql/009_task_completion.up.sql
+
+ALTER TABLE tasks ADD COLUMN completed_at DATETIME NULL;
+ALTER TABLE tasks ADD COLUMN reopened_at DATETIME NULL;
+ALTER TABLE tasks ADD COLUMN reopened_by TEXT NULL CHECK (length(reopened_by) <= 100);
+
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed';
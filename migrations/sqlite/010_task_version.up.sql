@@ -0,0 +1,4 @@
+-- Migration 010 (SQLite): Versão da task (controle de concorrência otimista)
+-- Equivalente a migrations/mysql/010_task_version.up.sql
+
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
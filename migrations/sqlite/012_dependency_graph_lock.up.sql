@@ -0,0 +1,9 @@
+-- Migration 012 (SQLite): Lock do grafo de dependências
+-- Equivalente a migrations/mysql/012_dependency_graph_lock.up.sql. No SQLite a transação
+-- já serializa as escritas; a tabela existe para o repository ser o mesmo nos três bancos.
+
+CREATE TABLE IF NOT EXISTS dependency_graph_lock (
+    id INTEGER NOT NULL PRIMARY KEY
+);
+
+INSERT OR IGNORE INTO dependency_graph_lock (id) VALUES (1);
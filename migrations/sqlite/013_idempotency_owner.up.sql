@@ -0,0 +1,4 @@
+-- Migration 013 (SQLite): Dono da reserva de idempotência
+-- Equivalente a migrations/mysql/013_idempotency_owner.up.sql
+
+ALTER TABLE idempotency_keys ADD COLUMN owner TEXT NOT NULL DEFAULT '';